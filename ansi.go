@@ -0,0 +1,259 @@
+package termboxUI
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nsf/termbox-go"
+)
+
+//======================================================//
+// ANSI Escape Sequences
+//======================================================//
+
+// An ANSIParser turns text with ANSI escape sequences, such as the colored output of a command, into styled spans.
+// SGR sequences set the style of the text that follows them: bold, underline, reverse, reset, and the 16 basic,
+// 256 indexed and 24-bit colors. All other escape sequences and control characters except '\n' and '\t' are removed.
+// The style carries over from one call of Parse to the next, as does an escape sequence that was cut off at the end of the text.
+type ANSIParser struct {
+	// Colors256 maps colors to the 256 colors of termbox.Output256, which has to be set as the output mode.
+	// Otherwise colors are reduced to the eight basic ones, and bright foreground colors are drawn in bold.
+	Colors256 bool
+
+	style   Span
+	bright  bool // the foreground is a bright color, drawn in bold without Colors256
+	pending string
+}
+
+// An escape sequence that isn't complete after this many bytes is dropped, and what follows it is shown as text.
+const maxEscapeLength = 256
+
+// ParseANSI converts a string with ANSI escape sequences into styled spans, starting from the default style.
+func ParseANSI(text string) []Span {
+	return new(ANSIParser).Parse(text)
+}
+
+// Parse converts the text into styled spans.
+func (p *ANSIParser) Parse(text string) []Span {
+	text = p.pending + text
+	p.pending = ""
+
+	var spans []Span
+	var run strings.Builder
+	flush := func() {
+		if run.Len() > 0 {
+			span := p.style.withText(run.String())
+			span.Bold = span.Bold || p.bright
+			spans = append(spans, span)
+			run.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		ch := text[i]
+		switch {
+		case ch == 0x1b:
+			size, params, final, complete := scanEscape(text[i:])
+			if !complete {
+				if len(text)-i < maxEscapeLength {
+					p.pending = text[i:]
+					i = len(text)
+				} else {
+					i++
+				}
+				continue
+			}
+			if final == 'm' {
+				flush()
+				p.applySGR(params)
+			}
+			i += size
+		case ch == '\n' || ch == '\t':
+			run.WriteByte(ch)
+			i++
+		case ch < 0x20 || ch == 0x7f:
+			// Other control characters, such as '\r' and the bell, have nothing to draw.
+			i++
+		default:
+			run.WriteByte(ch)
+			i++
+		}
+	}
+
+	flush()
+	return spans
+}
+
+// Find the length of the escape sequence at the start of the text.
+// For a CSI sequence the parameters and the final byte are returned as well; for others the final byte is 0.
+// 'complete' is false when the text ends before the sequence does.
+func scanEscape(text string) (size int, params string, final byte, complete bool) {
+	if len(text) < 2 {
+		return 0, "", 0, false
+	}
+
+	switch text[1] {
+	case '[':
+		for i := 2; i < len(text); i++ {
+			switch ch := text[i]; {
+			case ch >= 0x20 && ch <= 0x3f:
+				// Parameter and intermediate bytes.
+			case ch >= 0x40 && ch <= 0x7e:
+				return i + 1, text[2:i], ch, true
+			default:
+				// Not a valid sequence; drop what was read of it.
+				return i, "", 0, true
+			}
+		}
+		return 0, "", 0, false
+	case ']', 'P', 'X', '^', '_':
+		// Strings such as window titles end with BEL or ST.
+		for i := 2; i < len(text); i++ {
+			if text[i] == 0x07 {
+				return i + 1, "", 0, true
+			}
+			if text[i] == 0x1b && i+1 < len(text) && text[i+1] == '\\' {
+				return i + 2, "", 0, true
+			}
+		}
+		return 0, "", 0, false
+	case '(', ')', '*', '+':
+		// Character set designations.
+		if len(text) < 3 {
+			return 0, "", 0, false
+		}
+		return 3, "", 0, true
+	default:
+		return 2, "", 0, true
+	}
+}
+
+// Apply the parameters of an SGR sequence to the current style.
+func (p *ANSIParser) applySGR(params string) {
+	if len(params) > 0 && strings.ContainsAny(params[:1], "<=>?") {
+		// Private sequences are not SGR.
+		return
+	}
+
+	// An empty parameter counts as 0, so a sequence without any parameters is a reset.
+	fields := strings.Split(strings.Replace(params, ":", ";", -1), ";")
+	codes := make([]int, len(fields))
+	for i, field := range fields {
+		codes[i], _ = strconv.Atoi(field)
+	}
+
+	for i := 0; i < len(codes); i++ {
+		switch code := codes[i]; {
+		case code == 0:
+			p.style = Span{}
+			p.bright = false
+		case code == 1:
+			p.style.Bold = true
+		case code == 4:
+			p.style.Underline = true
+		case code == 7:
+			p.style.Reverse = true
+		case code == 22:
+			p.style.Bold = false
+			p.bright = false
+		case code == 24:
+			p.style.Underline = false
+		case code == 27:
+			p.style.Reverse = false
+		case code >= 30 && code <= 37:
+			p.style.Fg, p.bright = p.color(code - 30)
+		case code == 39:
+			p.style.Fg = termbox.ColorDefault
+			p.bright = false
+		case code >= 40 && code <= 47:
+			p.style.Bg, _ = p.color(code - 40)
+		case code == 49:
+			p.style.Bg = termbox.ColorDefault
+		case code >= 90 && code <= 97:
+			p.style.Fg, p.bright = p.color(code - 90 + 8)
+		case code >= 100 && code <= 107:
+			p.style.Bg, _ = p.color(code - 100 + 8)
+		case code == 38 || code == 48:
+			index, used := extendedColor(codes[i+1:])
+			i += used
+			if index < 0 {
+				continue
+			}
+			if code == 38 {
+				p.style.Fg, p.bright = p.color(index)
+			} else {
+				p.style.Bg, _ = p.color(index)
+			}
+		}
+	}
+}
+
+// Read the color of a '38' or '48' code, which is either '5;index' or '2;r;g;b'.
+// The color is returned as an index into the 256 color palette, or -1 if it is not valid, along with the number of codes used.
+func extendedColor(codes []int) (index, used int) {
+	if len(codes) >= 2 && codes[0] == 5 {
+		if codes[1] < 0 || codes[1] > 255 {
+			return -1, 2
+		}
+		return codes[1], 2
+	}
+	if len(codes) >= 4 && codes[0] == 2 {
+		// The nearest color of the 6x6x6 color cube.
+		level := func(value int) int {
+			if value < 48 {
+				return 0
+			}
+			if value < 115 {
+				return 1
+			}
+			if value > 255 {
+				value = 255
+			}
+			return (value - 35) / 40
+		}
+		return 16 + 36*level(codes[1]) + 6*level(codes[2]) + level(codes[3]), 4
+	}
+	if len(codes) >= 1 {
+		return -1, 1
+	}
+	return -1, 0
+}
+
+// The termbox attribute for an index into the 256 color palette.
+// Without Colors256, 'bright' reports a bright color that was reduced to a basic one, which is drawn in bold as a foreground.
+// That bold is kept apart from the bold of the style, and is cleared by SGR 0, 22 and 39 or by the next foreground color.
+func (p *ANSIParser) color(index int) (color termbox.Attribute, bright bool) {
+	if p.Colors256 {
+		return termbox.Attribute(index + 1), false
+	}
+
+	switch {
+	case index < 8:
+	case index < 16:
+		index -= 8
+		bright = true
+	case index < 232:
+		// Reduce the color cube to whether each of red, green and blue is on.
+		cube := index - 16
+		red, green, blue := cube/36, cube/6%6, cube%6
+		index = 0
+		if red > 2 {
+			index |= 1
+		}
+		if green > 2 {
+			index |= 2
+		}
+		if blue > 2 {
+			index |= 4
+		}
+	default:
+		// The gray ramp becomes either dark gray or white.
+		if index < 244 {
+			index, bright = 0, true
+		} else {
+			index = 7
+		}
+	}
+
+	return termbox.ColorBlack + termbox.Attribute(index), bright
+}
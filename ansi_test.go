@@ -0,0 +1,62 @@
+package termboxUI
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestParseANSI(t *testing.T) {
+	got := ParseANSI("plain \x1b[1;31mred\x1b[0m \x1b[4munder\x1b[24m\x1b]0;title\x07done\r\n")
+	want := []Span{
+		{Text: "plain "},
+		{Text: "red", Fg: termbox.ColorRed, Bold: true},
+		{Text: " "},
+		{Text: "under", Underline: true},
+		{Text: "done\n"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseANSI = %+v, want %+v", got, want)
+	}
+}
+
+func TestANSIParserKeepsSequencesAcrossCalls(t *testing.T) {
+	var parser ANSIParser
+	first := parser.Parse("a\x1b[3")
+	second := parser.Parse("2mb")
+	if want := []Span{{Text: "a"}}; !reflect.DeepEqual(first, want) {
+		t.Errorf("first Parse = %+v, want %+v", first, want)
+	}
+	if want := []Span{{Text: "b", Fg: termbox.ColorGreen}}; !reflect.DeepEqual(second, want) {
+		t.Errorf("second Parse = %+v, want %+v", second, want)
+	}
+}
+
+func TestANSIBrightColorsAreBoldUntilCleared(t *testing.T) {
+	for _, reset := range []string{"22", "39", "0", "32"} {
+		spans := ParseANSI("\x1b[91mbright\x1b[" + reset + "mafter")
+		if len(spans) != 2 {
+			t.Fatalf("SGR %s: got %+v", reset, spans)
+		}
+		if !spans[0].Bold || spans[0].Fg != termbox.ColorRed {
+			t.Errorf("SGR %s: bright red is drawn as %+v", reset, spans[0])
+		}
+		if spans[1].Bold || spans[1].Fg&termbox.AttrBold != 0 {
+			t.Errorf("SGR %s: the text after it is still bold: %+v", reset, spans[1])
+		}
+	}
+
+	spans := (&ANSIParser{Colors256: true}).Parse("\x1b[91mbright")
+	if want := []Span{{Text: "bright", Fg: termbox.Attribute(10)}}; !reflect.DeepEqual(spans, want) {
+		t.Errorf("with Colors256, got %+v, want %+v", spans, want)
+	}
+}
+
+func TestANSIExtendedColors(t *testing.T) {
+	parser := ANSIParser{Colors256: true}
+	spans := parser.Parse("\x1b[38;5;196;48;2;0;0;255mx")
+	if want := []Span{{Text: "x", Fg: termbox.Attribute(197), Bg: termbox.Attribute(22)}}; !reflect.DeepEqual(spans, want) {
+		t.Errorf("got %+v, want %+v", spans, want)
+	}
+}
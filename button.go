@@ -5,6 +5,8 @@ import (
 )
 
 // A button is a simple button field that can be cast to a command.
+// The button is drawn highlighted while it is Active, which is the case whenever it has focus.
+// With Markup set, the text can be styled with markup tags, see ParseMarkup.
 type Button struct {
 	Text   string
 	Height int
@@ -13,6 +15,7 @@ type Button struct {
 	Fg     termbox.Attribute
 	Bg     termbox.Attribute
 	Active bool
+	Markup bool
 }
 
 // Creates an instance of a Button
@@ -45,16 +48,42 @@ func (b *Button) Draw(x, y int) {
 	}
 
 	textbox := CreateTextBox(b.Width, b.Height, true, false, TextAlignmentDefault, TextAlignmentDefault, fg, bg)
-	textbox.AddText(b.Text)
+	textbox.addText(b.Text, b.Markup)
 	textbox.Draw(x, y)
 }
 
+// Highlight the button while it has focus.
+func (b *Button) HandleFocus(focused bool) {
+	b.Active = focused
+}
+
 func (b *Button) HandleKey(key termbox.Key, ch rune, event chan UIEvent) bool {
 	switch key {
 	case termbox.KeyEnter:
-		event <- b.Event
+		b.send(event)
 		return true
 	default:
 		return false
 	}
 }
+
+// The size of the area the button is drawn in.
+func (b *Button) Size() (width, height int) {
+	return b.Width, b.Height
+}
+
+// A left click sends the button's event, the same as pressing 'Enter'.
+func (b *Button) HandleMouse(x, y int, key termbox.Key, event chan UIEvent) bool {
+	if key != termbox.MouseLeft {
+		return false
+	}
+	b.send(event)
+	return true
+}
+
+// Send the button's event from its own goroutine, like a menu command, so the UI never waits on itself.
+func (b *Button) send(event chan UIEvent) {
+	go func(result UIEvent) {
+		event <- result
+	}(b.Event)
+}
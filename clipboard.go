@@ -0,0 +1,67 @@
+package termboxUI
+
+import (
+	"encoding/base64"
+	"io"
+	"sync"
+)
+
+//============================//
+//         Clipboard          //
+//----------------------------//
+
+// A clipboard holds the text that is cut or copied from an edit box, ready to be pasted.
+type Clipboard interface {
+	SetText(text string) error
+	Text() (string, error)
+}
+
+// The clipboard used by edit boxes that don't have one of their own.
+var DefaultClipboard Clipboard = new(MemoryClipboard)
+
+// A memory clipboard keeps its text within the program. It is safe to use from several goroutines.
+type MemoryClipboard struct {
+	mutex sync.Mutex
+	text  string
+}
+
+func (c *MemoryClipboard) SetText(text string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.text = text
+	return nil
+}
+
+func (c *MemoryClipboard) Text() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.text, nil
+}
+
+// An OSC 52 clipboard copies text to the system clipboard through the terminal, which also works over SSH
+// in terminals that support it. The escape sequence is written to Output, or to the terminal the UI is drawn on if Output
+// is nil, which is /dev/tty even when standard output is redirected.
+// Terminals rarely let a program read the clipboard, so Text returns the text last copied by this program.
+// Text copied elsewhere can still be pasted with the terminal's own paste, which arrives as bracketed paste.
+type OSC52Clipboard struct {
+	Output io.Writer
+
+	memory MemoryClipboard
+}
+
+func (c *OSC52Clipboard) SetText(text string) error {
+	c.memory.SetText(text)
+
+	output := c.Output
+	if output == nil {
+		terminal, closeTerminal := openTerminal()
+		defer closeTerminal()
+		output = terminal
+	}
+	_, err := io.WriteString(output, "\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte(text))+"\x07")
+	return err
+}
+
+func (c *OSC52Clipboard) Text() (string, error) {
+	return c.memory.Text()
+}
@@ -0,0 +1,92 @@
+package termboxUI
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+type failingClipboard struct{ err error }
+
+func (c failingClipboard) SetText(text string) error { return c.err }
+func (c failingClipboard) Text() (string, error)     { return "", c.err }
+
+func TestClipboardErrorsDoNotBlock(t *testing.T) {
+	failure := errors.New("no clipboard")
+	eb := CreateEditBox(20, "text", 3, termbox.ColorDefault, termbox.ColorDefault)
+	eb.Clipboard = failingClipboard{failure}
+	eb.SelectAll()
+
+	// The channel already holds the result of another key, as it can while the UI handles a key.
+	ev := make(chan UIEvent, 1)
+	ev <- NewStringEvent(0, "earlier")
+
+	done := make(chan struct{})
+	go func() {
+		EditCopy(eb, ev)
+		EditPaste(eb, ev)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("a clipboard error blocked the edit box")
+	}
+
+	<-ev
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-ev:
+			if err, _ := event.ErrorResult(); err == nil || err.Error() != failure.Error() || event.CustomType != 3 {
+				t.Errorf("got %v with custom type %d, want the clipboard error", err, event.CustomType)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("the clipboard error was not reported")
+		}
+	}
+}
+
+func TestOSC52ClipboardWritesToOutput(t *testing.T) {
+	var output bytes.Buffer
+	clipboard := &OSC52Clipboard{Output: &output}
+
+	if err := clipboard.SetText("hi"); err != nil {
+		t.Fatal(err)
+	}
+	if got := output.String(); got != "\x1b]52;c;aGk=\x07" {
+		t.Errorf("wrote %q", got)
+	}
+	if text, _ := clipboard.Text(); text != "hi" {
+		t.Errorf("Text returned %q", text)
+	}
+}
+
+func TestSelectCutAndPaste(t *testing.T) {
+	eb := CreateEditBox(20, "hello world", 0, termbox.ColorDefault, termbox.ColorDefault)
+	eb.Clipboard = new(MemoryClipboard)
+	eb.CursorIndex = len(eb.Value)
+	ev := make(chan UIEvent, 1)
+
+	pressKeys(eb, ev, KeyBinding{Key: KeyCtrlShiftArrowLeft})
+	if start, end := eb.Selection(); start != 6 || end != 11 {
+		t.Fatalf("Ctrl+Shift+Left selected %d-%d", start, end)
+	}
+	pressKeys(eb, ev, KeyBinding{Key: termbox.KeyCtrlX})
+	if string(eb.Value) != "hello " {
+		t.Errorf("cutting left %q", string(eb.Value))
+	}
+
+	pressKeys(eb, ev, KeyBinding{Key: termbox.KeyHome}, KeyBinding{Key: termbox.KeyCtrlV})
+	if string(eb.Value) != "worldhello " || eb.CursorIndex != 5 {
+		t.Errorf("pasting gave %q with the cursor at %d", string(eb.Value), eb.CursorIndex)
+	}
+
+	// Typing over a selection replaces it.
+	pressKeys(eb, ev, KeyBinding{Key: KeyShiftHome}, "W")
+	if string(eb.Value) != "Whello " {
+		t.Errorf("typing over the selection gave %q", string(eb.Value))
+	}
+}
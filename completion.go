@@ -0,0 +1,290 @@
+package termboxUI
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nsf/termbox-go"
+)
+
+//============================//
+//         Completion         //
+//----------------------------//
+
+// A Completer suggests ways to complete the text of an edit box.
+// Complete is given the text and the cursor index, and returns the candidates along with the index where the text they
+// replace starts. The text from there up to the cursor is replaced by the chosen candidate.
+type Completer interface {
+	Complete(value []rune, cursor int) (candidates []string, start int)
+}
+
+// CompleterFunc lets an ordinary function be used as a Completer.
+type CompleterFunc func(value []rune, cursor int) (candidates []string, start int)
+
+func (f CompleterFunc) Complete(value []rune, cursor int) ([]string, int) {
+	return f(value, cursor)
+}
+
+// A WordCompleter completes the word before the cursor from a list of words, such as the commands of a prompt.
+// Words match fuzzily, so "gst" suggests "git-status", and the best matches come first. See FuzzyMatch.
+type WordCompleter struct {
+	Words []string
+}
+
+func (wc WordCompleter) Complete(value []rune, cursor int) ([]string, int) {
+	cursor = clampIndex(cursor, len(value))
+	start := cursor
+	for start > 0 && !unicode.IsSpace(value[start-1]) {
+		start--
+	}
+	return FuzzyFilter(string(value[start:cursor]), wc.Words), start
+}
+
+// FuzzyFilter returns the candidates that FuzzyMatch the pattern, the best matches first.
+// Candidates that match equally well keep their order.
+func FuzzyFilter(pattern string, candidates []string) []string {
+	type match struct {
+		candidate string
+		score     int
+	}
+	var matches []match
+	for _, candidate := range candidates {
+		if score, ok := FuzzyMatch(pattern, candidate); ok {
+			matches = append(matches, match{candidate, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	filtered := make([]string, len(matches))
+	for i, m := range matches {
+		filtered[i] = m.candidate
+	}
+	return filtered
+}
+
+// FuzzyMatch reports whether the characters of the pattern appear in the candidate in the same order, ignoring case.
+// The score is higher for better matches: characters that follow one another, that start the candidate or one of its
+// words, or that have the same case count for more, and characters skipped before the first match count against it.
+func FuzzyMatch(pattern, candidate string) (score int, ok bool) {
+	score, _, ok = fuzzyMatch([]rune(pattern), []rune(candidate))
+	return
+}
+
+// Match the pattern against the candidate, returning the indices of the matched characters as well.
+func fuzzyMatch(pattern, candidate []rune) (score int, positions []int, ok bool) {
+	at := 0
+	for _, ch := range pattern {
+		for at < len(candidate) && unicode.ToLower(candidate[at]) != unicode.ToLower(ch) {
+			at++
+		}
+		if at == len(candidate) {
+			return 0, nil, false
+		}
+
+		score++
+		switch {
+		case len(positions) > 0 && positions[len(positions)-1] == at-1:
+			score += 5
+		case at == 0 || wordStart(candidate, at):
+			score += 8
+		}
+		if candidate[at] == ch {
+			score++
+		}
+		positions = append(positions, at)
+		at++
+	}
+
+	if len(positions) > 0 {
+		score -= positions[0]
+	}
+	return score, positions, true
+}
+
+// Whether the character at 'index' starts a word, after a separator or as a capital letter after a lower case one.
+func wordStart(text []rune, index int) bool {
+	previous := text[index-1]
+	return !unicode.IsLetter(previous) && !unicode.IsDigit(previous) ||
+		unicode.IsLower(previous) && unicode.IsUpper(text[index])
+}
+
+// The longest prefix that all of the strings start with.
+func commonPrefix(texts []string) string {
+	if len(texts) == 0 {
+		return ""
+	}
+	prefix := texts[0]
+	for _, text := range texts[1:] {
+		end := 0
+		for end < len(prefix) && end < len(text) && prefix[end] == text[end] {
+			end++
+		}
+		// Don't cut a character in half.
+		for end > 0 && end < len(prefix) && !utf8.RuneStart(prefix[end]) {
+			end--
+		}
+		prefix = prefix[:end]
+	}
+	return prefix
+}
+
+//============================//
+//   Edit Box Completion      //
+//----------------------------//
+
+// With a Completer, 'Tab' in an edit box completes as much of the text as all of the candidates have in common.
+// When more than one candidate is left, they are listed below the edit box. The arrows move through the list,
+// 'Tab' or 'Enter' takes the highlighted candidate and 'Esc' closes the list. Typing keeps the list up to date.
+
+// The number of suggestions listed at once. The list scrolls to show the others.
+const suggestionRows = 8
+
+// The list of suggestions shown below an edit box.
+type completion struct {
+	active     bool
+	candidates []string
+	start      int // where the text the candidates replace starts
+	selected   int
+	top        int // the first candidate shown
+}
+
+// Complete the text at the cursor, listing the candidates when there is more than one.
+func (eb *EditBox) complete() {
+	eb.selecting = false
+	candidates, start := eb.Completer.Complete(eb.Value, eb.CursorIndex)
+	start = clampIndex(start, eb.CursorIndex)
+
+	switch len(candidates) {
+	case 0:
+		eb.completion = completion{}
+	case 1:
+		eb.completion = completion{}
+		eb.replaceCompletion(start, candidates[0])
+	default:
+		if prefix := commonPrefix(candidates); utf8.RuneCountInString(prefix) > eb.CursorIndex-start {
+			eb.replaceCompletion(start, prefix)
+		}
+		eb.completion = completion{active: true, candidates: candidates, start: start}
+	}
+}
+
+// Replace the text from 'start' up to the cursor.
+func (eb *EditBox) replaceCompletion(start int, text string) {
+	eb.Edit(func() {
+		end := clampIndex(eb.CursorIndex, len(eb.Value))
+		value := append([]rune(nil), eb.Value[:start]...)
+		value = append(value, []rune(text)...)
+		eb.CursorIndex = len(value)
+		eb.Value = append(value, eb.Value[end:]...)
+	})
+}
+
+// Handle a key while the suggestions are listed. The return value is 'false' if the key has nothing to do with them.
+func (eb *EditBox) handleCompletionKey(binding KeyBinding) bool {
+	if binding.Mod != 0 {
+		return false
+	}
+
+	c := &eb.completion
+	switch binding.Key {
+	case termbox.KeyArrowUp, termbox.KeyCtrlP:
+		c.selected = (c.selected + len(c.candidates) - 1) % len(c.candidates)
+	case termbox.KeyArrowDown, termbox.KeyCtrlN:
+		c.selected = (c.selected + 1) % len(c.candidates)
+	case termbox.KeyTab, termbox.KeyEnter:
+		start, candidate := c.start, c.candidates[c.selected]
+		eb.completion = completion{}
+		eb.replaceCompletion(start, candidate)
+	case termbox.KeyEsc:
+		eb.completion = completion{}
+	default:
+		return false
+	}
+	return true
+}
+
+// Bring the suggestions up to date after a key, closing the list if the text did not change or nothing matches any more.
+func (eb *EditBox) updateCompletion(before string) {
+	if !eb.completion.active || string(eb.Value) == before || eb.Completer == nil {
+		eb.completion = completion{}
+		return
+	}
+
+	candidates, start := eb.Completer.Complete(eb.Value, eb.CursorIndex)
+	if len(candidates) == 0 {
+		eb.completion = completion{}
+		return
+	}
+	eb.completion = completion{active: true, candidates: candidates, start: clampIndex(start, eb.CursorIndex)}
+}
+
+// DrawOverlay lists the suggestions below the edit box, or above it if there is no room below.
+// The list starts under the text being completed, with the matched characters in bold.
+func (eb *EditBox) DrawOverlay(x, y int) {
+	c := &eb.completion
+	if !c.active || !eb.focused {
+		return
+	}
+
+	rows := len(c.candidates)
+	if rows > suggestionRows {
+		rows = suggestionRows
+	}
+	if c.selected < c.top {
+		c.top = c.selected
+	}
+	if c.selected >= c.top+rows {
+		c.top = c.selected - rows + 1
+	}
+
+	width := 0
+	for _, candidate := range c.candidates[c.top : c.top+rows] {
+		if candidateWidth := StringWidth(candidate) + 2; candidateWidth > width {
+			width = candidateWidth
+		}
+	}
+
+	screenWidth, screenHeight := activeScreen.Size()
+	listX := x + StringWidth(eb.prompt()) + columnOfIndex(eb.Value, c.start) - eb.scrollX
+	if listX+width > screenWidth {
+		listX = screenWidth - width
+	}
+	if listX < 0 {
+		listX = 0
+	}
+	// The text is on the middle row of the edit box.
+	listY := y + 3
+	if listY+rows > screenHeight && y+2-rows >= 0 {
+		listY = y + 2 - rows
+	}
+
+	end := clampIndex(eb.CursorIndex, len(eb.Value))
+	word := eb.Value[clampIndex(c.start, end):end]
+	for i := 0; i < rows; i++ {
+		index := c.top + i
+		fg := eb.Fg | termbox.AttrReverse
+		if index == c.selected {
+			fg = eb.Fg
+		}
+		FillArea(listX, listY+i, width, 1, fg, eb.Bg)
+		drawSpansClipped(listX+1, listY+i, width-1, 0, suggestionSpans(word, c.candidates[index]), fg, eb.Bg)
+	}
+}
+
+// The spans of a suggestion, with the characters that match the text being completed in bold.
+func suggestionSpans(word []rune, candidate string) []Span {
+	runes := []rune(candidate)
+	_, positions, ok := fuzzyMatch(word, runes)
+	if !ok {
+		return []Span{{Text: candidate}}
+	}
+
+	var spans []Span
+	from := 0
+	for _, at := range positions {
+		spans = append(spans, Span{Text: string(runes[from:at])}, Span{Text: string(runes[at]), Bold: true})
+		from = at + 1
+	}
+	return append(spans, Span{Text: string(runes[from:])})
+}
@@ -0,0 +1,77 @@
+package termboxUI
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	if _, ok := FuzzyMatch("gst", "git-status"); !ok {
+		t.Error("gst does not match git-status")
+	}
+	if _, ok := FuzzyMatch("sg", "git-status"); ok {
+		t.Error("characters out of order matched")
+	}
+	prefix, _ := FuzzyMatch("st", "status")
+	inside, _ := FuzzyMatch("st", "restore")
+	if prefix <= inside {
+		t.Errorf("a prefix scored %d, not more than a match inside a word at %d", prefix, inside)
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	got := FuzzyFilter("co", []string{"echo", "commit", "checkout", "clone"})
+	if want := []string{"commit", "checkout", "clone", "echo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("filtered %q, want %q", got, want)
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	if got := commonPrefix([]string{"résumé", "résister"}); got != "rés" {
+		t.Errorf("common prefix is %q", got)
+	}
+	if got := commonPrefix([]string{"éa", "èa"}); got != "" {
+		t.Errorf("common prefix of characters sharing a first byte is %q", got)
+	}
+}
+
+func TestTabCompletesInAnEditBox(t *testing.T) {
+	screen := NewSimulationScreen(40, 12)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	eb := CreateEditBox(30, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	eb.Completer = WordCompleter{Words: []string{"status", "stash", "commit"}}
+	eb.HandleFocus(true)
+	ev := make(chan UIEvent, 1)
+
+	pressKeys(eb, ev, "git co", KeyBinding{Key: termbox.KeyTab})
+	if string(eb.Value) != "git commit" || eb.completion.active {
+		t.Errorf("a single candidate completed to %q", string(eb.Value))
+	}
+
+	eb.SetValue("git st")
+	pressKeys(eb, ev, KeyBinding{Key: termbox.KeyTab})
+	if string(eb.Value) != "git sta" || !eb.completion.active {
+		t.Fatalf("two candidates completed to %q", string(eb.Value))
+	}
+
+	eb.Draw(0, 0)
+	eb.DrawOverlay(0, 0)
+	if !strings.Contains(screen.Line(3), "status") || !strings.Contains(screen.Line(4), "stash") {
+		t.Errorf("the suggestions are not listed below the edit box:\n%s", screen.String())
+	}
+
+	pressKeys(eb, ev, KeyBinding{Key: termbox.KeyArrowDown}, KeyBinding{Key: termbox.KeyEnter})
+	if string(eb.Value) != "git stash" || eb.completion.active {
+		t.Errorf("taking the second suggestion gave %q", string(eb.Value))
+	}
+	select {
+	case <-ev:
+		t.Error("Enter submitted the text instead of taking the suggestion")
+	default:
+	}
+}
@@ -0,0 +1,116 @@
+package termboxUI
+
+//==========================//
+//      Event Dispatch      //
+//==========================//
+
+// EventDispatcher handles a UI event. An error it returns is handled according to the UI's ErrorMode.
+type EventDispatcher func(event UIEvent) error
+
+// EventMiddleware is a step in the event pipeline that runs before the event handlers, such as logging or filtering.
+// It passes the event on by calling next, possibly with a modified event, or drops the event by returning without calling it.
+type EventMiddleware func(event UIEvent, next EventDispatcher) error
+
+// ErrorMode decides what happens to errors reported by UI events or returned by event handlers.
+type ErrorMode int
+
+// These are the possible ways to handle errors.
+const (
+	ErrorAbort ErrorMode = iota // stop the UI and return the error from StartUI, Start or Run. This is the default.
+	ErrorPopup                  // show the error in a popup at the bottom of the screen until the next key press.
+)
+
+// Use adds middleware to the end of the UI's event pipeline. Middleware runs in the order it was added.
+func (ui *UI) Use(middleware ...EventMiddleware) {
+	ui.Middleware = append(ui.Middleware, middleware...)
+}
+
+// OnType registers a handler for every event of the given result type. It replaces any handler already registered for that type.
+// Unlike the handlers in Events, it can return an error.
+func (ui *UI) OnType(resultType ResultType, handler EventDispatcher) {
+	if ui.typeHandlers == nil {
+		ui.typeHandlers = make(map[ResultType]EventDispatcher)
+	}
+	ui.typeHandlers[resultType] = handler
+}
+
+// OnCustom registers a handler for every event of the given custom type. It replaces any handler already registered for that type.
+// Unlike the handlers in CustomEvents, it can return an error.
+func (ui *UI) OnCustom(customType uint16, handler EventDispatcher) {
+	if ui.customHandlers == nil {
+		ui.customHandlers = make(map[uint16]EventDispatcher)
+	}
+	ui.customHandlers[customType] = handler
+}
+
+// DispatchEvent sends an event through the middleware and then on to the handlers:
+// first those for its result type (Events and OnType), then those for its custom type (CustomEvents and OnCustom).
+// If no handler exists for the event, it is given to OnUnhandled instead.
+// An event with its Error set goes through the middleware, which can log or drop it, but not on to the handlers;
+// the error is returned instead.
+func (ui *UI) DispatchEvent(event UIEvent) error {
+	next := ui.dispatchToHandlers
+	for i := len(ui.Middleware) - 1; i >= 0; i-- {
+		middleware, rest := ui.Middleware[i], next
+		next = func(event UIEvent) error {
+			return middleware(event, rest)
+		}
+	}
+	return next(event)
+}
+
+// The last step of the pipeline.
+func (ui *UI) dispatchToHandlers(event UIEvent) error {
+	if event.Error != nil {
+		return event.Error
+	}
+
+	handled := false
+
+	if action, ok := ui.Events[event.Type]; ok {
+		action(event)
+		handled = true
+	}
+	if handler, ok := ui.typeHandlers[event.Type]; ok {
+		handled = true
+		if err := handler(event); err != nil {
+			return err
+		}
+	}
+	if action, ok := ui.CustomEvents[event.CustomType]; ok {
+		action(event)
+		handled = true
+	}
+	if handler, ok := ui.customHandlers[event.CustomType]; ok {
+		handled = true
+		if err := handler(event); err != nil {
+			return err
+		}
+	}
+
+	if !handled && ui.OnUnhandled != nil {
+		ui.OnUnhandled(event)
+	}
+	return nil
+}
+
+// Apply the ErrorMode to an error from the pipeline. The error is returned if the UI has to stop.
+func (ui *UI) handleError(err error) error {
+	if err == nil || ui.ErrorMode != ErrorPopup {
+		return err
+	}
+
+	ui.errorPopup = CreatePopup("ERROR", err.Error(), PopupBottom, 6, -1, ui.Fg, ui.Bg)
+	ui.Invalidate()
+	return nil
+}
+
+// Hide the error popup. The return value is 'false' if there was no popup to hide.
+func (ui *UI) dismissError() bool {
+	if ui.errorPopup == nil {
+		return false
+	}
+	ui.errorPopup = nil
+	ui.Invalidate()
+	return true
+}
@@ -0,0 +1,71 @@
+package termboxUI
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// A UI whose middleware and handlers record the order they run in.
+func recordingUI(calls *[]string) *UI {
+	ui := new(UI)
+	ui.Use(func(event UIEvent, next EventDispatcher) error {
+		*calls = append(*calls, "first")
+		return next(event)
+	})
+	ui.Use(func(event UIEvent, next EventDispatcher) error {
+		*calls = append(*calls, "second")
+		if event.CustomType == 9 {
+			return nil
+		}
+		return next(event)
+	})
+	ui.Events = map[ResultType]func(UIEvent){UIResultInt: func(UIEvent) { *calls = append(*calls, "type") }}
+	ui.OnCustom(3, func(UIEvent) error {
+		*calls = append(*calls, "custom")
+		return errors.New("handler failed")
+	})
+	ui.OnUnhandled = func(UIEvent) { *calls = append(*calls, "unhandled") }
+	return ui
+}
+
+func TestDispatchRunsMiddlewareThenHandlers(t *testing.T) {
+	var calls []string
+	ui := recordingUI(&calls)
+
+	if err := ui.DispatchEvent(NewIntEvent(3, 1)); err == nil || err.Error() != "handler failed" {
+		t.Errorf("DispatchEvent returned %v", err)
+	}
+	if want := []string{"first", "second", "type", "custom"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("ran %v, want %v", calls, want)
+	}
+
+	calls = nil
+	ui.DispatchEvent(NewStringEvent(8, ""))
+	if want := []string{"first", "second", "unhandled"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("ran %v, want %v", calls, want)
+	}
+
+	calls = nil
+	ui.DispatchEvent(NewStringEvent(9, ""))
+	if want := []string{"first", "second"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("ran %v for a dropped event, want %v", calls, want)
+	}
+}
+
+func TestDispatchPassesErrorsThroughMiddleware(t *testing.T) {
+	var calls []string
+	ui := recordingUI(&calls)
+
+	failure := errors.New("failed")
+	if err := ui.DispatchEvent(UIEvent{Type: UIResultInt, CustomType: 3, Error: failure}); err != failure {
+		t.Errorf("DispatchEvent returned %v, want %v", err, failure)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("ran %v, want %v", calls, want)
+	}
+
+	if err := ui.DispatchEvent(UIEvent{CustomType: 9, Error: failure}); err != nil {
+		t.Errorf("DispatchEvent returned %v for an error dropped by the middleware", err)
+	}
+}
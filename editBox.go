@@ -1,7 +1,9 @@
 package termboxUI
 
 import (
-	"bytes"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/nsf/termbox-go"
 )
@@ -13,22 +15,55 @@ import (
 // An edit box or input box is a field that allows the user to input text.
 // A custom type can be set to help indicate the nature of the text being input.
 // For example, an input box could be for a first name or last name.
+// The cursor is only shown while the edit box has focus, and the prompt is drawn in bold to mark the focused box.
+// The editing keys are set by the KeyMap, which follows readline and Emacs unless it is replaced. See DefaultEditKeyMap.
+// While Mode names one of the Modes, its bindings are used in place of the KeyMap. See EditMode.
+// Edits can be undone, and UndoDepth limits the number of steps that are kept, or 0 for no limit.
+// Text can be selected with the keyboard or by double clicking a word, and the selection is drawn in reverse video.
+// Cut, copy and paste go through the Clipboard, or DefaultClipboard when it is nil.
+// With an InputHistory, submitted text is remembered and can be brought back with the arrow keys or searched with Ctrl+R.
+// With a Completer, 'Tab' completes the text and lists the suggestions below the edit box.
 type EditBox struct {
-	Width       int
-	Height      int
-	Value       []rune
-	Fg          termbox.Attribute
-	Bg          termbox.Attribute
-	CursorIndex int
-	CustomType  uint16
+	Width        int
+	Height       int
+	Value        []rune
+	Fg           termbox.Attribute
+	Bg           termbox.Attribute
+	CursorIndex  int
+	CustomType   uint16
+	UndoDepth    int
+	Clipboard    Clipboard
+	KeyMap       map[KeyBinding]EditAction // nil for DefaultEditKeyMap
+	Modes        map[string]EditMode       // other sets of bindings, which actions switch to with EditSetMode
+	Mode         string                    // the mode in use, or "" for the KeyMap
+	InputHistory *InputHistory             // the text submitted before, if it should be remembered
+	Completer    Completer                 // suggests completions on 'Tab', which then no longer moves the focus
+
+	focused    bool
+	scrollX    int // the column of the text shown first
+	history    editHistory
+	selecting  bool // the text between the anchor and the cursor is selected
+	anchor     int  // the end of the selection that stays put while the cursor moves
+	pasting    bool // the keys are part of a bracketed paste
+	pasted     []rune
+	kills      killRing
+	recall     historyRecall
+	search     historySearch
+	completion completion
+
+	lastClick      time.Time
+	lastClickIndex int
 }
 
+// Two clicks on the same character within this time select the word there.
+const doubleClickInterval = 400 * time.Millisecond
+
 // Creates a new instance of an edit box.
 // When width is -1, the text box will be the width of the terminal window.
 func CreateEditBox(width int, value string, customMessageCode uint16, fg, bg termbox.Attribute) *EditBox {
 	editBox := new(EditBox)
 
-	screenWidth, _ := termbox.Size()
+	screenWidth, _ := activeScreen.Size()
 
 	editBox.Width = width
 	if editBox.Width == -1 {
@@ -39,6 +74,7 @@ func CreateEditBox(width int, value string, customMessageCode uint16, fg, bg ter
 	editBox.Bg = bg
 
 	editBox.CustomType = customMessageCode
+	editBox.UndoDepth = DefaultUndoDepth
 
 	if len(value) > 0 {
 		editBox.Value = make([]rune, len(value))
@@ -51,135 +87,479 @@ func CreateEditBox(width int, value string, customMessageCode uint16, fg, bg ter
 	return editBox
 }
 
+// Text that is too long for the edit box scrolls sideways to keep the cursor in view. The value itself is never cut short.
 func (eb *EditBox) Draw(x, y int) {
-	// Convert the input editable string to an array of runes so characters can be added, modified or removed from the edit text.
-	if len(eb.Value) > eb.Width {
-		nchrs := make([]rune, eb.Width)
-		copy(nchrs, eb.Value[len(eb.Value)-eb.Width-1:])
-		eb.Value = nchrs
+	// The columns left for the text after the prompt, with room for the cursor after the last character.
+	prompt := eb.prompt()
+	promptWidth := StringWidth(prompt)
+	columns := eb.Width - promptWidth - 1
+	if columns < 1 {
+		columns = 1
+	}
+	cursorColumn := columnOfIndex(eb.Value, eb.CursorIndex)
+	if cursorColumn >= eb.scrollX+columns {
+		eb.scrollX = cursorColumn - columns + 1
+	}
+	if cursorColumn < eb.scrollX {
+		eb.scrollX = cursorColumn
 	}
-	displayString := string(eb.Value)
+
+	start := indexOfColumn(eb.Value, eb.scrollX)
+	display := []rune(TruncateString(string(eb.Value[start:]), columns))
+
+	// Split the visible text around the part of the selection that is in view.
+	selectionStart, selectionEnd := eb.Selection()
+	from := clampIndex(selectionStart-start, len(display))
+	to := clampIndex(selectionEnd-start, len(display))
 
 	textbox := CreateTextBox(eb.Width, 4, false, false, TextAlignmentDefault, TextAlignmentCenter, eb.Fg, eb.Bg)
-	textbox.AddText("/> " + displayString)
+	textbox.AddStyledText(
+		Span{Text: prompt + string(display[:from])},
+		Span{Text: string(display[from:to]), Reverse: true},
+		Span{Text: string(display[to:])},
+	)
 	textbox.Draw(x, y)
 
-	x_coord := x + eb.CursorIndex + 3
-	termbox.SetCursor(x_coord, y+2)
+	if eb.focused {
+		DrawText(x, y+2, prompt, eb.Fg|termbox.AttrBold, eb.Bg)
+
+		x_coord := x + columnOfIndex(eb.Value, eb.CursorIndex) - columnOfIndex(eb.Value, start) + promptWidth
+		activeScreen.SetCursor(x_coord, y+2)
+	}
 
 	return
 }
 
-// Handles a termbox key or character input
-// 'Enter' or 'Return' will end the string editing and signal that editing is complete.
-// 'Backspace' removes the character before the currently selected character.
-// 'Delete' removes the currently selected character.
-// Left and right arrow keys will move the cursor along the edit string.
-// 'Tab' inserts four spaces to the run array.
-// 'Space' inserts a single space.
-// Otherwise the character input is added to the string.
-func (eb *EditBox) HandleKey(key termbox.Key, ch rune, ev chan UIEvent) (eventConsumed bool) {
-	eventConsumed = true
+// Show the cursor in the edit box while it has focus. The list of suggestions closes when the focus moves away.
+func (eb *EditBox) HandleFocus(focused bool) {
+	eb.focused = focused
+	if !focused {
+		eb.completion = completion{}
+	}
+}
 
-	switch key {
-	case termbox.KeyEnter:
-		// Send along the input
-		event := UIEvent{}
-		event.Type = UIResultString
-		event.CustomType = eb.CustomType
-		event.Data = bytes.NewBufferString(string(eb.Value))
+// SetValue replaces the text of the edit box and moves the cursor to its end. This can be undone like any other edit.
+func (eb *EditBox) SetValue(value string) {
+	eb.history.record(eb.state(), editOther, eb.UndoDepth)
+	eb.Value = []rune(value)
+	eb.CursorIndex = len(eb.Value)
+	eb.selecting = false
+}
+
+// Undo reverts the last edit. The return value is 'false' if there is nothing to undo.
+func (eb *EditBox) Undo() bool {
+	state, ok := eb.history.stepBack(eb.state())
+	eb.restore(state)
+	return ok
+}
+
+// Redo applies an edit again after it was undone. The return value is 'false' if there is nothing to redo.
+func (eb *EditBox) Redo() bool {
+	state, ok := eb.history.stepForward(eb.state())
+	eb.restore(state)
+	return ok
+}
+
+func (eb *EditBox) state() editState {
+	return editState{value: eb.Value, cursor: eb.CursorIndex}
+}
+
+func (eb *EditBox) restore(state editState) {
+	eb.Value = state.value
+	eb.CursorIndex = state.cursor
+	eb.selecting = false
+}
+
+// Selection returns the start and end indices of the selected text. They are both the cursor index when nothing is selected.
+func (eb *EditBox) Selection() (start, end int) {
+	if !eb.selecting {
+		return eb.CursorIndex, eb.CursorIndex
+	}
+	start, end = clampIndex(eb.anchor, len(eb.Value)), clampIndex(eb.CursorIndex, len(eb.Value))
+	if start > end {
+		start, end = end, start
+	}
+	return
+}
+
+// SelectAll selects the whole text and moves the cursor to its end.
+func (eb *EditBox) SelectAll() {
+	eb.anchor = 0
+	eb.CursorIndex = len(eb.Value)
+	eb.selecting = len(eb.Value) > 0
+}
+
+// SelectWord selects the word at the cursor. Between words, the run of spaces or punctuation there is selected instead.
+func (eb *EditBox) SelectWord() {
+	eb.anchor, eb.CursorIndex = wordBounds(eb.Value, eb.CursorIndex)
+	eb.selecting = eb.anchor < eb.CursorIndex
+}
+
+// Move the cursor while keeping the other end of the selection in place, starting a selection at the cursor if there is none.
+func (eb *EditBox) extendSelection(to int) {
+	if !eb.selecting {
+		eb.anchor = eb.CursorIndex
+	}
+	eb.CursorIndex = to
+	eb.selecting = eb.anchor != eb.CursorIndex
+}
+
+// Remove the selected text and leave the cursor where it was. Nothing happens without a selection.
+func (eb *EditBox) deleteSelection() {
+	if !eb.selecting {
+		return
+	}
+	start, end := eb.Selection()
+	eb.Value = append(eb.Value[:start:start], eb.Value[end:]...)
+	eb.CursorIndex = start
+	eb.selecting = false
+}
+
+// Insert text at the cursor in place of the selection, and move the cursor past it.
+func (eb *EditBox) insertText(text []rune) {
+	eb.deleteSelection()
+	index := clampIndex(eb.CursorIndex, len(eb.Value))
+
+	value := make([]rune, 0, len(eb.Value)+len(text))
+	value = append(value, eb.Value[:index]...)
+	value = append(value, text...)
+	eb.Value = append(value, eb.Value[index:]...)
+	eb.CursorIndex = index + len(text)
+}
+
+func (eb *EditBox) clipboard() Clipboard {
+	if eb.Clipboard == nil {
+		return DefaultClipboard
+	}
+	return eb.Clipboard
+}
+
+// Copy the selection to the clipboard, removing it from the text when 'cut' is set.
+// A clipboard error is sent as an error event with the custom type of the edit box.
+func (eb *EditBox) copySelection(cut bool, ev chan UIEvent) {
+	if !eb.selecting {
+		return
+	}
+	start, end := eb.Selection()
+	if err := eb.clipboard().SetText(string(eb.Value[start:end])); err != nil {
+		eb.reportError(err, ev)
+		return
+	}
+	if cut {
+		eb.deleteSelection()
+	}
+}
+
+// Send an error event with the custom type of the edit box. The event is sent from a goroutine of its own, since the
+// channel may already hold the result of the key being handled and the UI only reads it once the key is done.
+func (eb *EditBox) reportError(err error, ev chan UIEvent) {
+	event := NewErrorEvent(eb.CustomType, err)
+	go func() {
 		ev <- event
+	}()
+}
 
-		//Clear the edit buffer
-		eb.Value = make([]rune, 0)
-		eb.CursorIndex = 0
+// Paste the text on the clipboard in place of the selection.
+func (eb *EditBox) paste(ev chan UIEvent) {
+	text, err := eb.clipboard().Text()
+	if err != nil {
+		eb.reportError(err, ev)
+		return
+	}
+	eb.insertText(singleLine(text))
+}
 
-	case termbox.KeyBackspace2:
-		startLength := len(eb.Value)
-		eb.Value = removeCharacter(eb.Value, eb.CursorIndex-1)
-		if startLength > len(eb.Value) {
-			eb.CursorIndex = setCursor(eb.CursorIndex, eb.CursorIndex-1, len(eb.Value))
-		}
-	case termbox.KeyDelete:
-		eb.Value = removeCharacter(eb.Value, eb.CursorIndex)
-	case termbox.KeyArrowRight:
-		eb.CursorIndex = setCursor(eb.CursorIndex, eb.CursorIndex+1, len(eb.Value))
-	case termbox.KeyArrowLeft:
-		eb.CursorIndex = setCursor(eb.CursorIndex, eb.CursorIndex-1, len(eb.Value))
+// Collect the keys of a bracketed paste. The pasted text is inserted when the paste ends, as a single edit.
+func (eb *EditBox) handlePasteKey(key termbox.Key, ch rune) {
+	switch key {
+	case KeyPasteEnd:
+		eb.pasting = false
+		eb.Edit(func() { eb.insertText(singleLine(string(eb.pasted))) })
+		eb.pasted = nil
+	case termbox.KeyEnter:
+		eb.pasted = append(eb.pasted, '\r')
+	case termbox.KeyCtrlJ:
+		eb.pasted = append(eb.pasted, '\n')
 	case termbox.KeyTab:
-		startLength := len(eb.Value)
-		eb.Value = insertCharacter(eb.Value, ' ', eb.CursorIndex)
-		eb.Value = insertCharacter(eb.Value, ' ', eb.CursorIndex)
-		eb.Value = insertCharacter(eb.Value, ' ', eb.CursorIndex)
-		eb.Value = insertCharacter(eb.Value, ' ', eb.CursorIndex)
-		if startLength < len(eb.Value) {
-			eb.CursorIndex = setCursor(eb.CursorIndex, eb.CursorIndex+4, len(eb.Value))
-		}
+		eb.pasted = append(eb.pasted, '\t')
 	case termbox.KeySpace:
-		startLength := len(eb.Value)
-		eb.Value = insertCharacter(eb.Value, ' ', eb.CursorIndex)
-		if startLength < len(eb.Value) {
-			eb.CursorIndex = setCursor(eb.CursorIndex, eb.CursorIndex+1, len(eb.Value))
-		}
+		eb.pasted = append(eb.pasted, ' ')
 	default:
 		if ch != 0 {
-			startLength := len(eb.Value)
-			eb.Value = insertCharacter(eb.Value, ch, eb.CursorIndex)
-			if startLength < len(eb.Value) {
-				eb.CursorIndex = setCursor(eb.CursorIndex, eb.CursorIndex+1, len(eb.Value))
-			}
-		} else {
-			eventConsumed = false
+			eb.pasted = append(eb.pasted, ch)
+		}
+	}
+}
+
+// During a bracketed paste or a history search all keys go to the edit box.
+// A 'Ctrl+C' bound to EditCopy copies while text is selected instead of quitting.
+// With a Completer, 'Tab' completes instead of moving the focus, and 'Esc' closes the list of suggestions.
+func (eb *EditBox) ClaimsKey(key termbox.Key, ch rune) bool {
+	_, bound := eb.keyMap()[KeyBinding{Key: termbox.KeyCtrlC}]
+	return eb.pasting || eb.search.active ||
+		key == termbox.KeyCtrlC && eb.selecting && bound ||
+		key == termbox.KeyTab && eb.Completer != nil ||
+		key == termbox.KeyEsc && eb.completion.active
+}
+
+// Handles a termbox key or character input. The keys are looked up in the KeyMap without any modifiers.
+func (eb *EditBox) HandleKey(key termbox.Key, ch rune, ev chan UIEvent) (eventConsumed bool) {
+	return eb.HandleKeyBinding(KeyBinding{Key: key, Ch: ch}, ev)
+}
+
+// Handles a key press along with its modifiers.
+// The key runs the action it is bound to in the current mode, the KeyMap, or DefaultEditKeyMap when the KeyMap is nil.
+// 'Space' and characters without a binding are typed into the text, replacing the selection, unless the mode is not for typing. Consecutive typing is undone in one step.
+// Text pasted into the terminal is inserted as it is, with line breaks and tabs turned into spaces.
+func (eb *EditBox) HandleKeyBinding(binding KeyBinding, ev chan UIEvent) (eventConsumed bool) {
+	eventConsumed = true
+
+	if eb.pasting {
+		eb.handlePasteKey(binding.Key, binding.Ch)
+		return
+	}
+	if eb.search.active && eb.handleHistorySearchKey(binding) {
+		return
+	}
+	if binding.Key == KeyPasteStart {
+		eb.pasting = true
+		eb.pasted = nil
+		return
+	}
+
+	eb.CursorIndex = clampIndex(eb.CursorIndex, len(eb.Value))
+	eb.kills.next()
+
+	if eb.completion.active {
+		if eb.handleCompletionKey(binding) {
+			return
 		}
+		defer eb.updateCompletion(string(eb.Value))
 	}
+	if eb.Completer != nil && binding.Key == termbox.KeyTab && binding.Mod == 0 {
+		eb.complete()
+		return
+	}
+
+	if action, ok := eb.keyMap()[binding]; ok {
+		eb.history.interrupt()
+		action(eb, ev)
+		return
+	}
+
+	if mode, ok := eb.Modes[eb.Mode]; ok && !mode.Typing {
+		return false
+	}
+	ch := binding.Ch
+	if binding.Key == termbox.KeySpace {
+		ch = ' '
+	}
+	if ch == 0 || binding.Key != 0 && binding.Key != termbox.KeySpace || binding.Mod != 0 {
+		return false
+	}
+
+	// Typing over a selection starts a new undo step.
+	kind := editTyping
+	if eb.selecting {
+		kind = editOther
+	}
+	eb.edit(kind, func() { eb.insertText([]rune{ch}) })
 	return
 }
 
+func (eb *EditBox) keyMap() map[KeyBinding]EditAction {
+	if mode, ok := eb.Modes[eb.Mode]; ok {
+		return mode.KeyMap
+	}
+	if eb.KeyMap == nil {
+		return defaultEditKeyMap
+	}
+	return eb.KeyMap
+}
+
+// Edit makes a change to the text that can be undone as a single step.
+func (eb *EditBox) Edit(change func()) {
+	eb.edit(editOther, change)
+}
+
+func (eb *EditBox) edit(kind editKind, change func()) {
+	before := eb.state()
+	change()
+	if string(before.value) != string(eb.Value) {
+		eb.history.record(before, kind, eb.UndoDepth)
+	}
+}
+
+// Move the cursor, dropping the selection.
+func (eb *EditBox) moveCursor(to int) {
+	eb.CursorIndex = clampIndex(to, len(eb.Value))
+	eb.selecting = false
+}
+
+// The size of the area the edit box is drawn in.
+func (eb *EditBox) Size() (width, height int) {
+	return eb.Width, 4
+}
+
+// A left click moves the cursor to the clicked character, or to the end of the text when clicking past it.
+// Clicking either half of a wide character places the cursor before it. A double click selects the word that was clicked.
+func (eb *EditBox) HandleMouse(x, y int, key termbox.Key, ev chan UIEvent) bool {
+	if key != termbox.MouseLeft {
+		return false
+	}
+
+	if eb.search.active {
+		eb.acceptHistorySearch()
+	}
+	eb.completion = completion{}
+
+	// The text starts after the "/> " prompt.
+	eb.CursorIndex = indexOfColumn(eb.Value, x-StringWidth(eb.prompt())+eb.scrollX)
+	eb.selecting = false
+	eb.history.interrupt()
+
+	now := time.Now()
+	if eb.CursorIndex == eb.lastClickIndex && now.Sub(eb.lastClick) < doubleClickInterval {
+		eb.SelectWord()
+		// A third click starts over rather than counting as another double click.
+		now = time.Time{}
+	}
+	eb.lastClick, eb.lastClickIndex = now, eb.CursorIndex
+	return true
+}
+
 //============================//
 //         Utilities          //
 //----------------------------//
 
-// Insert a character into the rune array at a specified index.
-// If the index is greater than the length of the edit rune array, it will be appended to the end of the array.
-func insertCharacter(dst []rune, ch rune, index int) []rune {
-	slice := make([]rune, len(dst)+1)
+// The display column of the character at 'index' in the rune array, counting wide characters as two columns.
+func columnOfIndex(value []rune, index int) int {
+	if index > len(value) {
+		index = len(value)
+	}
+	if index < 0 {
+		index = 0
+	}
+	return RunesWidth(value[:index])
+}
+
+// The index of the cluster displayed at 'column', or the length of the rune array if the column is past the end of the text.
+func indexOfColumn(value []rune, column int) int {
+	index := 0
+	for index < len(value) {
+		next := nextClusterIndex(value, index)
+		width := RunesWidth(value[index:next])
+		if column < width {
+			break
+		}
+		column -= width
+		index = next
+	}
+	return index
+}
+
+// The index of the start of the cluster after the one at 'index'. Combining marks are skipped along with their base character.
+// The cursor never moves past the end of the text.
+func nextClusterIndex(value []rune, index int) int {
+	if index >= len(value) {
+		return len(value)
+	}
+	text := string(value[index:])
+	_, _, size := nextGrapheme(text)
+	return index + utf8.RuneCountInString(text[:size])
+}
+
+// The index of the start of the cluster before the one at 'index'.
+func previousClusterIndex(value []rune, index int) int {
+	previous := 0
+	for i := 0; i < index && i < len(value); i = nextClusterIndex(value, i) {
+		previous = i
+	}
+	return previous
+}
+
+// Keep an index within a rune array of the given length.
+func clampIndex(index, length int) int {
+	if index < 0 {
+		return 0
+	}
+	if index > length {
+		return length
+	}
+	return index
+}
+
+// Letters, digits, underscores and combining marks make up words.
+func isWordRune(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch) || unicode.IsMark(ch) || ch == '_'
+}
 
+// The kind of character for finding the bounds of a word: a word character, a space or anything else.
+func wordClass(ch rune) int {
 	switch {
-	case index == len(dst):
-		//add the character to the end of the array
-		copy(slice, dst)
-		slice[len(dst)] = ch
-		return slice
-	case len(dst) > index:
-		// insert the character into the array
-		copy(slice, dst[:index])
-		slice[index] = ch
-		copy(slice[index+1:], dst[index:])
-		return slice
+	case isWordRune(ch):
+		return 0
+	case unicode.IsSpace(ch):
+		return 1
 	default:
-		return dst
+		return 2
 	}
 }
 
-// Remove the character from the rune array that is specifid by 'index.' if it is a valide character within the edit string.
-func removeCharacter(dst []rune, index int) []rune {
-	if index >= 0 && index < len(dst) {
-		slice := make([]rune, len(dst)-1)
-		copy(slice, dst[:index])
-		copy(slice[index:], dst[index+1:])
-		return slice
+// The start and end of the run of characters of the same kind as the one at 'index'. At the end of the text, the last run is used.
+func wordBounds(value []rune, index int) (start, end int) {
+	if len(value) == 0 {
+		return 0, 0
 	}
-	return dst
+	index = clampIndex(index, len(value)-1)
+
+	class := wordClass(value[index])
+	start, end = index, index+1
+	for start > 0 && wordClass(value[start-1]) == class {
+		start--
+	}
+	for end < len(value) && wordClass(value[end]) == class {
+		end++
+	}
+	return
 }
 
-// Determine the index of the active/highlighted character in the edit string.
-func setCursor(from, to, inputBoxLength int) (newIndex int) {
-	newIndex = from
+// The index of the start of the word before 'index', skipping anything between words.
+func previousWordIndex(value []rune, index int) int {
+	index = clampIndex(index, len(value))
+	for index > 0 && !isWordRune(value[index-1]) {
+		index--
+	}
+	for index > 0 && isWordRune(value[index-1]) {
+		index--
+	}
+	return index
+}
 
-	if from < to && from >= 0 ||
-		from > to && to >= 0 {
-		newIndex = to
+// The index just past the end of the word after 'index', skipping anything between words.
+func nextWordIndex(value []rune, index int) int {
+	index = clampIndex(index, len(value))
+	for index < len(value) && !isWordRune(value[index]) {
+		index++
+	}
+	for index < len(value) && isWordRune(value[index]) {
+		index++
 	}
+	return index
+}
 
-	return
+// Make text fit on a single line. Line breaks and tabs become spaces, a "\r\n" pair becoming a single space,
+// and other control characters are left out.
+func singleLine(text string) []rune {
+	var line []rune
+	for i, ch := range text {
+		switch {
+		case ch == '\n' && i > 0 && text[i-1] == '\r':
+		case ch == '\n', ch == '\r', ch == '\t':
+			line = append(line, ' ')
+		case !unicode.IsControl(ch):
+			line = append(line, ch)
+		}
+	}
+	return line
 }
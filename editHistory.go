@@ -0,0 +1,78 @@
+package termboxUI
+
+//============================//
+//        Edit History        //
+//----------------------------//
+
+// The number of steps an edit box can undo unless UndoDepth is changed.
+const DefaultUndoDepth = 100
+
+// The kinds of edits, which decide whether an edit joins the undo step of the one before it.
+type editKind int
+
+const (
+	editNone   editKind = iota // not an edit, such as moving the cursor
+	editTyping                 // a typed character; consecutive typing is undone in one step
+	editOther
+)
+
+// The value and cursor of an edit box at some point in its history.
+type editState struct {
+	value  []rune
+	cursor int
+}
+
+// The undo and redo stacks of an edit box.
+type editHistory struct {
+	undo []editState
+	redo []editState
+	last editKind
+}
+
+// Remember the state from before an edit so that it can be undone.
+// Typing that follows typing is part of the same step. A depth above 0 limits the number of steps kept.
+func (h *editHistory) record(before editState, kind editKind, depth int) {
+	h.redo = nil
+	if kind == editTyping && h.last == editTyping && len(h.undo) > 0 {
+		return
+	}
+	h.last = kind
+
+	before.value = append([]rune(nil), before.value...)
+	h.undo = append(h.undo, before)
+	if depth > 0 && len(h.undo) > depth {
+		h.undo = append(h.undo[:0], h.undo[len(h.undo)-depth:]...)
+	}
+}
+
+// Note something other than an edit, which ends the current undo step.
+func (h *editHistory) interrupt() {
+	h.last = editNone
+}
+
+// Step back through the history. The current state is kept for redo, and the state to return to is returned.
+func (h *editHistory) stepBack(current editState) (editState, bool) {
+	return h.step(&h.undo, &h.redo, current)
+}
+
+// Step forward again after stepping back.
+func (h *editHistory) stepForward(current editState) (editState, bool) {
+	return h.step(&h.redo, &h.undo, current)
+}
+
+func (h *editHistory) step(from, to *[]editState, current editState) (editState, bool) {
+	if len(*from) == 0 {
+		return current, false
+	}
+	h.last = editNone
+
+	state := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, current)
+	return state, true
+}
+
+// Forget the whole history.
+func (h *editHistory) clear() {
+	*h = editHistory{}
+}
@@ -0,0 +1,62 @@
+package termboxUI
+
+import (
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestUndoStepsOverTypingAsAWhole(t *testing.T) {
+	eb := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ev := make(chan UIEvent, 1)
+
+	pressKeys(eb, ev, "ab", KeyBinding{Key: termbox.KeyArrowLeft}, "x", KeyBinding{Key: termbox.KeyBackspace2})
+	steps := []string{"axb", "ab", ""}
+	for _, want := range steps {
+		if !eb.Undo() {
+			t.Fatalf("nothing to undo before %q", want)
+		}
+		if string(eb.Value) != want {
+			t.Errorf("undo gave %q, want %q", string(eb.Value), want)
+		}
+	}
+	if eb.Undo() {
+		t.Error("undo went past the first edit")
+	}
+
+	for i := len(steps) - 2; i >= 0; i-- {
+		eb.Redo()
+		if string(eb.Value) != steps[i] {
+			t.Errorf("redo gave %q, want %q", string(eb.Value), steps[i])
+		}
+	}
+	if eb.Redo(); string(eb.Value) != "ab" || eb.CursorIndex != 1 {
+		t.Errorf("the last redo gave %q with the cursor at %d", string(eb.Value), eb.CursorIndex)
+	}
+}
+
+func TestEditingAfterUndoDropsRedo(t *testing.T) {
+	eb := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ev := make(chan UIEvent, 1)
+
+	pressKeys(eb, ev, "ab")
+	eb.Undo()
+	pressKeys(eb, ev, "c")
+	if eb.Redo() || string(eb.Value) != "c" {
+		t.Errorf("redo after a new edit gave %q", string(eb.Value))
+	}
+}
+
+func TestUndoDepth(t *testing.T) {
+	eb := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	eb.UndoDepth = 2
+	for _, value := range []string{"one", "two", "three"} {
+		eb.SetValue(value)
+	}
+
+	eb.Undo()
+	eb.Undo()
+	if eb.Undo() || string(eb.Value) != "one" {
+		t.Errorf("with a depth of 2, undoing everything left %q", string(eb.Value))
+	}
+}
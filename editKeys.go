@@ -0,0 +1,336 @@
+package termboxUI
+
+import (
+	"unicode"
+
+	"github.com/nsf/termbox-go"
+)
+
+//============================//
+//       Edit Box Keys        //
+//----------------------------//
+
+// EditAction is an editing command of an edit box, run when the key it is bound to is pressed.
+// An action that changes the text should do so through EditBox.Edit so that the change can be undone.
+type EditAction func(eb *EditBox, ev chan UIEvent)
+
+// These are the built-in editing commands that can be bound to keys in an edit box's KeyMap.
+var (
+	// EditSubmit sends the text as a string event with the custom type of the edit box, then clears it and its edit history.
+	// The text is added to the InputHistory if there is one.
+	EditSubmit EditAction = func(eb *EditBox, ev chan UIEvent) {
+		ev <- NewStringEvent(eb.CustomType, string(eb.Value))
+		eb.remember(ev)
+
+		eb.Value = make([]rune, 0)
+		eb.CursorIndex = 0
+		eb.selecting = false
+		eb.history.clear()
+	}
+
+	EditUndo EditAction = func(eb *EditBox, ev chan UIEvent) { eb.Undo() }
+	EditRedo EditAction = func(eb *EditBox, ev chan UIEvent) { eb.Redo() }
+
+	// EditLeft and EditRight move the cursor by a character. With text selected, the cursor goes to that end of the selection.
+	EditLeft EditAction = func(eb *EditBox, ev chan UIEvent) {
+		if eb.selecting {
+			start, _ := eb.Selection()
+			eb.moveCursor(start)
+			return
+		}
+		eb.moveCursor(previousClusterIndex(eb.Value, eb.CursorIndex))
+	}
+	EditRight EditAction = func(eb *EditBox, ev chan UIEvent) {
+		if eb.selecting {
+			_, end := eb.Selection()
+			eb.moveCursor(end)
+			return
+		}
+		eb.moveCursor(nextClusterIndex(eb.Value, eb.CursorIndex))
+	}
+	EditHome      EditAction = func(eb *EditBox, ev chan UIEvent) { eb.moveCursor(0) }
+	EditEnd       EditAction = func(eb *EditBox, ev chan UIEvent) { eb.moveCursor(len(eb.Value)) }
+	EditWordLeft  EditAction = func(eb *EditBox, ev chan UIEvent) { eb.moveCursor(previousWordIndex(eb.Value, eb.CursorIndex)) }
+	EditWordRight EditAction = func(eb *EditBox, ev chan UIEvent) { eb.moveCursor(nextWordIndex(eb.Value, eb.CursorIndex)) }
+
+	// The selection actions move the cursor like the actions above while keeping the other end of the selection in place.
+	EditSelectLeft EditAction = func(eb *EditBox, ev chan UIEvent) {
+		eb.extendSelection(previousClusterIndex(eb.Value, eb.CursorIndex))
+	}
+	EditSelectRight EditAction = func(eb *EditBox, ev chan UIEvent) {
+		eb.extendSelection(nextClusterIndex(eb.Value, eb.CursorIndex))
+	}
+	EditSelectHome     EditAction = func(eb *EditBox, ev chan UIEvent) { eb.extendSelection(0) }
+	EditSelectEnd      EditAction = func(eb *EditBox, ev chan UIEvent) { eb.extendSelection(len(eb.Value)) }
+	EditSelectWordLeft EditAction = func(eb *EditBox, ev chan UIEvent) {
+		eb.extendSelection(previousWordIndex(eb.Value, eb.CursorIndex))
+	}
+	EditSelectWordRight EditAction = func(eb *EditBox, ev chan UIEvent) {
+		eb.extendSelection(nextWordIndex(eb.Value, eb.CursorIndex))
+	}
+	EditSelectAll EditAction = func(eb *EditBox, ev chan UIEvent) { eb.SelectAll() }
+
+	// EditCut, EditCopy and EditPaste go through the edit box's Clipboard.
+	EditCut   EditAction = func(eb *EditBox, ev chan UIEvent) { eb.Edit(func() { eb.copySelection(true, ev) }) }
+	EditCopy  EditAction = func(eb *EditBox, ev chan UIEvent) { eb.copySelection(false, ev) }
+	EditPaste EditAction = func(eb *EditBox, ev chan UIEvent) { eb.Edit(func() { eb.paste(ev) }) }
+
+	// EditBackspace and EditDelete remove the character before or at the cursor, or the selection if there is one.
+	EditBackspace EditAction = func(eb *EditBox, ev chan UIEvent) {
+		eb.Edit(func() {
+			if !eb.selecting {
+				eb.extendSelection(previousClusterIndex(eb.Value, eb.CursorIndex))
+			}
+			eb.deleteSelection()
+		})
+	}
+	EditDelete EditAction = func(eb *EditBox, ev chan UIEvent) {
+		eb.Edit(func() {
+			if !eb.selecting {
+				eb.extendSelection(nextClusterIndex(eb.Value, eb.CursorIndex))
+			}
+			eb.deleteSelection()
+		})
+	}
+
+	// EditTab inserts four spaces.
+	EditTab EditAction = func(eb *EditBox, ev chan UIEvent) {
+		eb.Edit(func() { eb.insertText([]rune("    ")) })
+	}
+
+	// The kill actions remove text onto the edit box's kill ring. Kills in a row are joined into a single entry.
+	// EditKillToSpace removes the text back to the previous space, like Ctrl+W does in a shell.
+	EditKillToSpace EditAction = func(eb *EditBox, ev chan UIEvent) {
+		start := clampIndex(eb.CursorIndex, len(eb.Value))
+		for start > 0 && unicode.IsSpace(eb.Value[start-1]) {
+			start--
+		}
+		for start > 0 && !unicode.IsSpace(eb.Value[start-1]) {
+			start--
+		}
+		eb.kill(start, eb.CursorIndex)
+	}
+	EditKillWordLeft EditAction = func(eb *EditBox, ev chan UIEvent) {
+		eb.kill(previousWordIndex(eb.Value, eb.CursorIndex), eb.CursorIndex)
+	}
+	EditKillWordRight EditAction = func(eb *EditBox, ev chan UIEvent) {
+		eb.kill(eb.CursorIndex, nextWordIndex(eb.Value, eb.CursorIndex))
+	}
+	EditKillToStart EditAction = func(eb *EditBox, ev chan UIEvent) { eb.kill(0, eb.CursorIndex) }
+	EditKillToEnd   EditAction = func(eb *EditBox, ev chan UIEvent) { eb.kill(eb.CursorIndex, len(eb.Value)) }
+
+	// EditHistoryPrevious and EditHistoryNext step through the InputHistory. EditHistorySearch searches back through it.
+	EditHistoryPrevious EditAction = func(eb *EditBox, ev chan UIEvent) { eb.recallHistory(-1) }
+	EditHistoryNext     EditAction = func(eb *EditBox, ev chan UIEvent) { eb.recallHistory(1) }
+	EditHistorySearch   EditAction = func(eb *EditBox, ev chan UIEvent) { eb.startHistorySearch() }
+
+	// EditYank inserts the last killed text. EditYankPop, right after a yank, swaps the yanked text for the kill before it.
+	EditYank    EditAction = func(eb *EditBox, ev chan UIEvent) { eb.yank() }
+	EditYankPop EditAction = func(eb *EditBox, ev chan UIEvent) { eb.yankPop() }
+)
+
+// DefaultEditKeyMap returns the bindings used by an edit box that has no KeyMap of its own.
+// Along with the arrows, 'Home', 'End', 'Backspace' and 'Delete', they follow readline and Emacs:
+//
+//	Ctrl+A, Ctrl+E       start and end of the text
+//	Ctrl+B, Ctrl+F       back and forward a character
+//	Alt+B, Alt+F         back and forward a word
+//	Ctrl+D, Ctrl+H       delete the character at or before the cursor
+//	Ctrl+W               kill back to the previous space
+//	Alt+Backspace, Alt+D kill the word before or after the cursor
+//	Ctrl+U, Ctrl+K       kill to the start or end of the text
+//	Alt+Y, Alt+Shift+Y   yank the last kill, then cycle through older kills
+//	Ctrl+Z, Ctrl+_       undo
+//	Ctrl+Y               redo
+//	Alt+A                select everything
+//	Up, Ctrl+P           the previous entry of the InputHistory
+//	Down, Ctrl+N         the next entry of the InputHistory
+//	Ctrl+R               search back through the InputHistory
+//
+// Shift with the arrows, 'Home' and 'End' selects text, and Ctrl+Shift with the arrows selects words.
+// 'Ctrl+X', 'Ctrl+C' and 'Ctrl+V' cut, copy and paste. 'Enter' submits the text and 'Tab' inserts four spaces.
+// Readline yanks on Ctrl+Y, but here Ctrl+Y redoes as it does in most editors, so yanking is on Alt+Y instead.
+// ReadlineEditKeyMap has the readline bindings for both.
+func DefaultEditKeyMap() map[KeyBinding]EditAction {
+	return map[KeyBinding]EditAction{
+		{Key: termbox.KeyEnter}: EditSubmit,
+
+		{Key: termbox.KeyCtrlZ}:          EditUndo,
+		{Key: termbox.KeyCtrlUnderscore}: EditUndo,
+		{Key: termbox.KeyCtrlY}:          EditRedo,
+
+		{Key: termbox.KeyArrowLeft}:    EditLeft,
+		{Key: termbox.KeyCtrlB}:        EditLeft,
+		{Key: termbox.KeyArrowRight}:   EditRight,
+		{Key: termbox.KeyCtrlF}:        EditRight,
+		{Key: termbox.KeyHome}:         EditHome,
+		{Key: termbox.KeyCtrlA}:        EditHome,
+		{Key: termbox.KeyEnd}:          EditEnd,
+		{Key: termbox.KeyCtrlE}:        EditEnd,
+		{Ch: 'b', Mod: termbox.ModAlt}: EditWordLeft,
+		{Ch: 'f', Mod: termbox.ModAlt}: EditWordRight,
+
+		{Key: KeyShiftArrowLeft}:       EditSelectLeft,
+		{Key: KeyShiftArrowRight}:      EditSelectRight,
+		{Key: KeyShiftHome}:            EditSelectHome,
+		{Key: KeyShiftEnd}:             EditSelectEnd,
+		{Key: KeyCtrlShiftArrowLeft}:   EditSelectWordLeft,
+		{Key: KeyCtrlShiftArrowRight}:  EditSelectWordRight,
+		{Ch: 'a', Mod: termbox.ModAlt}: EditSelectAll,
+
+		{Key: termbox.KeyCtrlX}: EditCut,
+		{Key: termbox.KeyCtrlC}: EditCopy,
+		{Key: termbox.KeyCtrlV}: EditPaste,
+
+		{Key: termbox.KeyBackspace}:  EditBackspace,
+		{Key: termbox.KeyBackspace2}: EditBackspace,
+		{Key: termbox.KeyDelete}:     EditDelete,
+		{Key: termbox.KeyCtrlD}:      EditDelete,
+		{Key: termbox.KeyTab}:        EditTab,
+
+		{Key: termbox.KeyCtrlW}:                           EditKillToSpace,
+		{Key: termbox.KeyBackspace2, Mod: termbox.ModAlt}: EditKillWordLeft,
+		{Key: termbox.KeyBackspace, Mod: termbox.ModAlt}:  EditKillWordLeft,
+		{Ch: 'd', Mod: termbox.ModAlt}:                    EditKillWordRight,
+		{Key: termbox.KeyCtrlU}:                           EditKillToStart,
+		{Key: termbox.KeyCtrlK}:                           EditKillToEnd,
+		{Ch: 'y', Mod: termbox.ModAlt}:                    EditYank,
+		{Ch: 'Y', Mod: termbox.ModAlt}:                    EditYankPop,
+
+		{Key: termbox.KeyArrowUp}:   EditHistoryPrevious,
+		{Key: termbox.KeyCtrlP}:     EditHistoryPrevious,
+		{Key: termbox.KeyArrowDown}: EditHistoryNext,
+		{Key: termbox.KeyCtrlN}:     EditHistoryNext,
+		{Key: termbox.KeyCtrlR}:     EditHistorySearch,
+	}
+}
+
+// ReadlineEditKeyMap returns DefaultEditKeyMap with Ctrl+Y yanking and Alt+Y cycling through older kills, as in readline.
+// Redo moves to Ctrl+Alt+_, as in Emacs.
+func ReadlineEditKeyMap() map[KeyBinding]EditAction {
+	keyMap := DefaultEditKeyMap()
+	delete(keyMap, KeyBinding{Ch: 'Y', Mod: termbox.ModAlt})
+	keyMap[KeyBinding{Key: termbox.KeyCtrlY}] = EditYank
+	keyMap[KeyBinding{Ch: 'y', Mod: termbox.ModAlt}] = EditYankPop
+	keyMap[KeyBinding{Key: termbox.KeyCtrlUnderscore, Mod: termbox.ModAlt}] = EditRedo
+	return keyMap
+}
+
+// The bindings used by edit boxes that do not define a KeyMap.
+var defaultEditKeyMap = DefaultEditKeyMap()
+
+//============================//
+//        Edit Modes          //
+//----------------------------//
+
+// EditMode is a set of bindings that an edit box can switch to, for modal editing such as a vi profile,
+// where a key that moves the cursor in one mode is typed in another. See EditBox.Modes.
+// Characters without a binding are only typed into the text when Typing is set.
+// A key that switches modes and is also bound by the UI, such as 'Esc', can be given to the edit box with UI.BindField and a nil action.
+type EditMode struct {
+	KeyMap map[KeyBinding]EditAction
+	Typing bool
+}
+
+// EditSetMode creates an action that switches the edit box to one of its Modes, or back to its KeyMap with "".
+func EditSetMode(mode string) EditAction {
+	return func(eb *EditBox, ev chan UIEvent) { eb.Mode = mode }
+}
+
+//============================//
+//         Kill Ring          //
+//----------------------------//
+
+// The number of kills an edit box remembers.
+const killRingSize = 10
+
+// What a key did, for the kill and yank commands that carry on from the key before them.
+type killCommand int
+
+const (
+	killNone killCommand = iota
+	killText
+	killYank
+)
+
+// The kill ring of an edit box holds the text removed by the kill commands, the newest last.
+type killRing struct {
+	entries  [][]rune
+	previous killCommand // what the key before this one did
+	current  killCommand // what this key did
+
+	yankStart, yankEnd int // where the last yank put its text
+	yankIndex          int // the entry that was yanked
+}
+
+// Start a new key press, remembering what the last one did.
+func (r *killRing) next() {
+	r.previous = r.current
+	r.current = killNone
+}
+
+// Remove the text between two indices onto the kill ring. Text killed right after another kill joins its entry,
+// in front of it when killing backwards.
+func (eb *EditBox) kill(start, end int) {
+	eb.kills.current = killText
+	start, end = clampIndex(start, len(eb.Value)), clampIndex(end, len(eb.Value))
+	if start > end {
+		start, end = end, start
+	}
+	if start == end {
+		return
+	}
+
+	killed := append([]rune(nil), eb.Value[start:end]...)
+	ring := &eb.kills
+	switch {
+	case ring.previous != killText || len(ring.entries) == 0:
+		ring.entries = append(ring.entries, killed)
+		if len(ring.entries) > killRingSize {
+			ring.entries = ring.entries[1:]
+		}
+	case end <= eb.CursorIndex:
+		last := len(ring.entries) - 1
+		ring.entries[last] = append(killed, ring.entries[last]...)
+	default:
+		last := len(ring.entries) - 1
+		ring.entries[last] = append(ring.entries[last], killed...)
+	}
+
+	eb.Edit(func() {
+		eb.selecting = false
+		eb.Value = append(eb.Value[:start:start], eb.Value[end:]...)
+		eb.CursorIndex = start
+	})
+}
+
+// Insert the newest kill at the cursor.
+func (eb *EditBox) yank() {
+	ring := &eb.kills
+	if len(ring.entries) == 0 {
+		return
+	}
+	ring.current = killYank
+	ring.yankIndex = len(ring.entries) - 1
+
+	eb.Edit(func() { eb.insertText(ring.entries[ring.yankIndex]) })
+	ring.yankStart, ring.yankEnd = eb.CursorIndex-len(ring.entries[ring.yankIndex]), eb.CursorIndex
+}
+
+// Replace the text that was just yanked with the kill before it, going round to the newest after the oldest.
+func (eb *EditBox) yankPop() {
+	ring := &eb.kills
+	if ring.previous != killYank || len(ring.entries) == 0 {
+		return
+	}
+	ring.current = killYank
+	ring.yankIndex = (ring.yankIndex + len(ring.entries) - 1) % len(ring.entries)
+
+	eb.Edit(func() {
+		eb.selecting = false
+		eb.CursorIndex = ring.yankStart
+		eb.Value = append(eb.Value[:ring.yankStart:ring.yankStart], eb.Value[ring.yankEnd:]...)
+		eb.insertText(ring.entries[ring.yankIndex])
+	})
+	ring.yankEnd = eb.CursorIndex
+}
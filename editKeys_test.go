@@ -0,0 +1,101 @@
+package termboxUI
+
+import (
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Press keys in an edit box. A string is typed a character at a time.
+func pressKeys(eb *EditBox, ev chan UIEvent, keys ...interface{}) {
+	for _, key := range keys {
+		switch key := key.(type) {
+		case KeyBinding:
+			eb.HandleKeyBinding(key, ev)
+		case string:
+			for _, ch := range key {
+				eb.HandleKeyBinding(KeyBinding{Ch: ch}, ev)
+			}
+		}
+	}
+}
+
+func TestCtrlYRedoes(t *testing.T) {
+	eb := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ev := make(chan UIEvent, 1)
+
+	pressKeys(eb, ev, "abc", KeyBinding{Key: termbox.KeyCtrlZ})
+	if string(eb.Value) != "" {
+		t.Fatalf("after Ctrl+Z the text is %q", string(eb.Value))
+	}
+	pressKeys(eb, ev, KeyBinding{Key: termbox.KeyCtrlY})
+	if string(eb.Value) != "abc" {
+		t.Errorf("after Ctrl+Y the text is %q, want it redone", string(eb.Value))
+	}
+}
+
+func TestAltYYanksAndCyclesKills(t *testing.T) {
+	eb := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ev := make(chan UIEvent, 1)
+
+	// Typing between the kills keeps "one " and "two " apart on the kill ring.
+	pressKeys(eb, ev, "one ", KeyBinding{Key: termbox.KeyCtrlU}, "two ",
+		KeyBinding{Key: termbox.KeyCtrlW})
+	if string(eb.Value) != "" {
+		t.Fatalf("after killing the text is %q", string(eb.Value))
+	}
+
+	pressKeys(eb, ev, KeyBinding{Ch: 'y', Mod: termbox.ModAlt})
+	if string(eb.Value) != "two " {
+		t.Errorf("Alt+Y yanked %q, want the last kill", string(eb.Value))
+	}
+	pressKeys(eb, ev, KeyBinding{Ch: 'Y', Mod: termbox.ModAlt})
+	if string(eb.Value) != "one " {
+		t.Errorf("Alt+Shift+Y left %q, want the kill before", string(eb.Value))
+	}
+}
+
+func TestReadlineEditKeyMapYanksOnCtrlY(t *testing.T) {
+	eb := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	eb.KeyMap = ReadlineEditKeyMap()
+	ev := make(chan UIEvent, 1)
+
+	pressKeys(eb, ev, "one ", KeyBinding{Key: termbox.KeyCtrlU}, "two ", KeyBinding{Key: termbox.KeyCtrlW},
+		KeyBinding{Key: termbox.KeyCtrlY})
+	if string(eb.Value) != "two " {
+		t.Errorf("Ctrl+Y yanked %q, want the last kill", string(eb.Value))
+	}
+	pressKeys(eb, ev, KeyBinding{Ch: 'y', Mod: termbox.ModAlt})
+	if string(eb.Value) != "one " {
+		t.Errorf("Alt+Y left %q, want the kill before", string(eb.Value))
+	}
+
+	pressKeys(eb, ev, KeyBinding{Key: termbox.KeyCtrlZ}, KeyBinding{Key: termbox.KeyCtrlUnderscore, Mod: termbox.ModAlt})
+	if string(eb.Value) != "one " {
+		t.Errorf("undoing and redoing the yank left %q", string(eb.Value))
+	}
+}
+
+func TestEditModes(t *testing.T) {
+	eb := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ev := make(chan UIEvent, 1)
+
+	// A small vi profile: Esc switches to normal mode, where h and l move, x deletes and i goes back to inserting.
+	eb.KeyMap = DefaultEditKeyMap()
+	eb.KeyMap[KeyBinding{Key: termbox.KeyEsc}] = EditSetMode("normal")
+	eb.Modes = map[string]EditMode{"normal": {KeyMap: map[KeyBinding]EditAction{
+		{Ch: 'h'}: EditLeft,
+		{Ch: 'l'}: EditRight,
+		{Ch: 'x'}: EditDelete,
+		{Ch: 'i'}: EditSetMode(""),
+	}}}
+
+	pressKeys(eb, ev, "abc", KeyBinding{Key: termbox.KeyEsc}, "hhx")
+	if eb.HandleKey(0, 'z', ev) {
+		t.Error("a character without a binding was used in normal mode")
+	}
+	pressKeys(eb, ev, "iY")
+	if string(eb.Value) != "aYc" || eb.Mode != "" {
+		t.Errorf("editing in modes gave %q in mode %q", string(eb.Value), eb.Mode)
+	}
+}
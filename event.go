@@ -0,0 +1,422 @@
+package termboxUI
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+//==========================//
+//    UI Event Payloads     //
+//==========================//
+
+// The names of the result types, used in error messages.
+var resultTypeNames = map[ResultType]string{
+	UIResultBool:       "bool",
+	UIResultByte:       "byte",
+	UIResultComplex128: "complex128",
+	UIResultComplex64:  "complex64",
+	UIResultError:      "error",
+	UIResultFloat64:    "float64",
+	UIResultFloat32:    "float32",
+	UIResultInt:        "int",
+	UIResultInt8:       "int8",
+	UIResultInt16:      "int16",
+	UIResultInt32:      "int32",
+	UIResultInt64:      "int64",
+	UIResultRune:       "rune",
+	UIResultString:     "string",
+	UIResultUint:       "uint",
+	UIResultUint8:      "uint8",
+	UIResultUint16:     "uint16",
+	UIResultUint32:     "uint32",
+	UIResultUint64:     "uint64",
+	UIResultUintptr:    "uintptr",
+	UIResultJSON:       "JSON",
+	UIResultXML:        "XML",
+	UIResultMap:        "map",
+	UIResultSlice:      "slice",
+	UIResultNone:       "none",
+}
+
+// String returns the name of the result type, such as "int64".
+func (t ResultType) String() string {
+	if name, ok := resultTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("ResultType(%d)", uint16(t))
+}
+
+// ResultTypeError is returned by the UIEvent accessors when the event holds a different type of data than was asked for.
+type ResultTypeError struct {
+	Expected ResultType
+	Actual   ResultType
+}
+
+func (e *ResultTypeError) Error() string {
+	return fmt.Sprintf("termboxUI: event holds %v data, not %v", e.Actual, e.Expected)
+}
+
+//==========================//
+//       Constructors       //
+//==========================//
+
+// Fixed-size values are stored little endian, the same way the examples have always encoded them by hand.
+// Sizes that depend on the platform, such as int and uintptr, are always stored as 64 bits.
+func newFixedEvent(customType uint16, resultType ResultType, value interface{}) UIEvent {
+	data := new(bytes.Buffer)
+	if err := binary.Write(data, binary.LittleEndian, value); err != nil {
+		// Only fixed-size values are passed in, so this cannot happen.
+		panic(err)
+	}
+	return UIEvent{Type: resultType, CustomType: customType, Data: data}
+}
+
+// NewBoolEvent creates an event holding a bool, read back with UIEvent.Bool.
+func NewBoolEvent(customType uint16, value bool) UIEvent {
+	return newFixedEvent(customType, UIResultBool, value)
+}
+
+// NewByteEvent creates an event holding a byte, read back with UIEvent.Byte.
+func NewByteEvent(customType uint16, value byte) UIEvent {
+	return newFixedEvent(customType, UIResultByte, value)
+}
+
+// NewComplex128Event creates an event holding a complex128, read back with UIEvent.Complex128.
+func NewComplex128Event(customType uint16, value complex128) UIEvent {
+	return newFixedEvent(customType, UIResultComplex128, value)
+}
+
+// NewComplex64Event creates an event holding a complex64, read back with UIEvent.Complex64.
+func NewComplex64Event(customType uint16, value complex64) UIEvent {
+	return newFixedEvent(customType, UIResultComplex64, value)
+}
+
+// NewErrorEvent reports an error message as the result of a user interaction.
+// Unlike setting UIEvent.Error, this does not stop the UI; the error is only data for the event handlers.
+// A nil error is carried as no error at all, so ErrorResult gives back nil.
+func NewErrorEvent(customType uint16, value error) UIEvent {
+	if value == nil {
+		return UIEvent{Type: UIResultError, CustomType: customType, Data: new(bytes.Buffer)}
+	}
+	return UIEvent{Type: UIResultError, CustomType: customType, Data: bytes.NewBufferString(value.Error())}
+}
+
+// NewFloat64Event creates an event holding a float64, read back with UIEvent.Float64.
+func NewFloat64Event(customType uint16, value float64) UIEvent {
+	return newFixedEvent(customType, UIResultFloat64, value)
+}
+
+// NewFloat32Event creates an event holding a float32, read back with UIEvent.Float32.
+func NewFloat32Event(customType uint16, value float32) UIEvent {
+	return newFixedEvent(customType, UIResultFloat32, value)
+}
+
+// NewIntEvent creates an event holding a int, read back with UIEvent.Int. It is stored as 64 bits, whatever the platform.
+func NewIntEvent(customType uint16, value int) UIEvent {
+	return newFixedEvent(customType, UIResultInt, int64(value))
+}
+
+// NewInt8Event creates an event holding a int8, read back with UIEvent.Int8.
+func NewInt8Event(customType uint16, value int8) UIEvent {
+	return newFixedEvent(customType, UIResultInt8, value)
+}
+
+// NewInt16Event creates an event holding a int16, read back with UIEvent.Int16.
+func NewInt16Event(customType uint16, value int16) UIEvent {
+	return newFixedEvent(customType, UIResultInt16, value)
+}
+
+// NewInt32Event creates an event holding a int32, read back with UIEvent.Int32.
+func NewInt32Event(customType uint16, value int32) UIEvent {
+	return newFixedEvent(customType, UIResultInt32, value)
+}
+
+// NewInt64Event creates an event holding a int64, read back with UIEvent.Int64.
+func NewInt64Event(customType uint16, value int64) UIEvent {
+	return newFixedEvent(customType, UIResultInt64, value)
+}
+
+// NewRuneEvent creates an event holding a rune, read back with UIEvent.Rune.
+func NewRuneEvent(customType uint16, value rune) UIEvent {
+	return newFixedEvent(customType, UIResultRune, value)
+}
+
+// NewStringEvent creates an event holding a string, read back with UIEvent.StringResult.
+func NewStringEvent(customType uint16, value string) UIEvent {
+	return UIEvent{Type: UIResultString, CustomType: customType, Data: bytes.NewBufferString(value)}
+}
+
+// NewUintEvent creates an event holding a uint, read back with UIEvent.Uint. It is stored as 64 bits, whatever the platform.
+func NewUintEvent(customType uint16, value uint) UIEvent {
+	return newFixedEvent(customType, UIResultUint, uint64(value))
+}
+
+// NewUint8Event creates an event holding a uint8, read back with UIEvent.Uint8.
+func NewUint8Event(customType uint16, value uint8) UIEvent {
+	return newFixedEvent(customType, UIResultUint8, value)
+}
+
+// NewUint16Event creates an event holding a uint16, read back with UIEvent.Uint16.
+func NewUint16Event(customType uint16, value uint16) UIEvent {
+	return newFixedEvent(customType, UIResultUint16, value)
+}
+
+// NewUint32Event creates an event holding a uint32, read back with UIEvent.Uint32.
+func NewUint32Event(customType uint16, value uint32) UIEvent {
+	return newFixedEvent(customType, UIResultUint32, value)
+}
+
+// NewUint64Event creates an event holding a uint64, read back with UIEvent.Uint64.
+func NewUint64Event(customType uint16, value uint64) UIEvent {
+	return newFixedEvent(customType, UIResultUint64, value)
+}
+
+// NewUintptrEvent creates an event holding a uintptr, read back with UIEvent.Uintptr. It is stored as 64 bits, whatever the platform.
+func NewUintptrEvent(customType uint16, value uintptr) UIEvent {
+	return newFixedEvent(customType, UIResultUintptr, uint64(value))
+}
+
+// NewJSONEvent encodes the value as JSON. Decode it with UIEvent.JSON.
+func NewJSONEvent(customType uint16, value interface{}) (UIEvent, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return UIEvent{}, err
+	}
+	return UIEvent{Type: UIResultJSON, CustomType: customType, Data: bytes.NewBuffer(data)}, nil
+}
+
+// NewXMLEvent encodes the value as XML. Decode it with UIEvent.XML.
+func NewXMLEvent(customType uint16, value interface{}) (UIEvent, error) {
+	data, err := xml.Marshal(value)
+	if err != nil {
+		return UIEvent{}, err
+	}
+	return UIEvent{Type: UIResultXML, CustomType: customType, Data: bytes.NewBuffer(data)}, nil
+}
+
+// NewMapEvent encodes a map with encoding/gob. Decode it with UIEvent.Map.
+func NewMapEvent(customType uint16, value interface{}) (UIEvent, error) {
+	return newGobEvent(customType, UIResultMap, value)
+}
+
+// NewSliceEvent encodes a slice with encoding/gob. Decode it with UIEvent.Slice.
+func NewSliceEvent(customType uint16, value interface{}) (UIEvent, error) {
+	return newGobEvent(customType, UIResultSlice, value)
+}
+
+// Encode the value with encoding/gob.
+func newGobEvent(customType uint16, resultType ResultType, value interface{}) (UIEvent, error) {
+	data := new(bytes.Buffer)
+	if err := gob.NewEncoder(data).Encode(value); err != nil {
+		return UIEvent{}, err
+	}
+	return UIEvent{Type: resultType, CustomType: customType, Data: data}, nil
+}
+
+// NewNoneEvent creates an event that carries no data.
+func NewNoneEvent(customType uint16) UIEvent {
+	return UIEvent{Type: UIResultNone, CustomType: customType, Data: new(bytes.Buffer)}
+}
+
+//==========================//
+//        Accessors         //
+//==========================//
+
+// The accessors read the data without consuming it, so they can be called any number of times.
+func (e UIEvent) payload(expected ResultType) (*bytes.Reader, error) {
+	if e.Type != expected {
+		return nil, &ResultTypeError{Expected: expected, Actual: e.Type}
+	}
+	if e.Data == nil {
+		return bytes.NewReader(nil), nil
+	}
+	return bytes.NewReader(e.Data.Bytes()), nil
+}
+
+// Read a fixed-size value written by newFixedEvent.
+func (e UIEvent) readFixed(expected ResultType, value interface{}) error {
+	reader, err := e.payload(expected)
+	if err != nil {
+		return err
+	}
+	return binary.Read(reader, binary.LittleEndian, value)
+}
+
+// Bool returns the value of an event created with NewBoolEvent.
+func (e UIEvent) Bool() (value bool, err error) {
+	err = e.readFixed(UIResultBool, &value)
+	return
+}
+
+// Byte returns the value of an event created with NewByteEvent.
+func (e UIEvent) Byte() (value byte, err error) {
+	err = e.readFixed(UIResultByte, &value)
+	return
+}
+
+// Complex128 returns the value of an event created with NewComplex128Event.
+func (e UIEvent) Complex128() (value complex128, err error) {
+	err = e.readFixed(UIResultComplex128, &value)
+	return
+}
+
+// Complex64 returns the value of an event created with NewComplex64Event.
+func (e UIEvent) Complex64() (value complex64, err error) {
+	err = e.readFixed(UIResultComplex64, &value)
+	return
+}
+
+// ErrorResult returns the error reported with NewErrorEvent.
+// It is named so as not to clash with the Error field, which holds errors that stop the UI.
+func (e UIEvent) ErrorResult() (value error, err error) {
+	reader, err := e.payload(UIResultError)
+	if err != nil {
+		return nil, err
+	}
+	if reader.Len() == 0 {
+		return nil, nil
+	}
+	message := make([]byte, reader.Len())
+	reader.Read(message)
+	return errors.New(string(message)), nil
+}
+
+// Float64 returns the value of an event created with NewFloat64Event.
+func (e UIEvent) Float64() (value float64, err error) {
+	err = e.readFixed(UIResultFloat64, &value)
+	return
+}
+
+// Float32 returns the value of an event created with NewFloat32Event.
+func (e UIEvent) Float32() (value float32, err error) {
+	err = e.readFixed(UIResultFloat32, &value)
+	return
+}
+
+// Int returns the value of an event created with NewIntEvent.
+func (e UIEvent) Int() (int, error) {
+	var value int64
+	err := e.readFixed(UIResultInt, &value)
+	return int(value), err
+}
+
+// Int8 returns the value of an event created with NewInt8Event.
+func (e UIEvent) Int8() (value int8, err error) {
+	err = e.readFixed(UIResultInt8, &value)
+	return
+}
+
+// Int16 returns the value of an event created with NewInt16Event.
+func (e UIEvent) Int16() (value int16, err error) {
+	err = e.readFixed(UIResultInt16, &value)
+	return
+}
+
+// Int32 returns the value of an event created with NewInt32Event.
+func (e UIEvent) Int32() (value int32, err error) {
+	err = e.readFixed(UIResultInt32, &value)
+	return
+}
+
+// Int64 returns the value of an event created with NewInt64Event.
+func (e UIEvent) Int64() (value int64, err error) {
+	err = e.readFixed(UIResultInt64, &value)
+	return
+}
+
+// Rune returns the value of an event created with NewRuneEvent.
+func (e UIEvent) Rune() (value rune, err error) {
+	err = e.readFixed(UIResultRune, &value)
+	return
+}
+
+// StringResult returns the text of an event created with NewStringEvent, such as the value submitted by an EditBox.
+// Like ErrorResult, it is named so that UIEvent does not look like a fmt.Stringer.
+func (e UIEvent) StringResult() (string, error) {
+	reader, err := e.payload(UIResultString)
+	if err != nil {
+		return "", err
+	}
+	text := make([]byte, reader.Len())
+	reader.Read(text)
+	return string(text), nil
+}
+
+// Uint returns the value of an event created with NewUintEvent.
+func (e UIEvent) Uint() (uint, error) {
+	var value uint64
+	err := e.readFixed(UIResultUint, &value)
+	return uint(value), err
+}
+
+// Uint8 returns the value of an event created with NewUint8Event.
+func (e UIEvent) Uint8() (value uint8, err error) {
+	err = e.readFixed(UIResultUint8, &value)
+	return
+}
+
+// Uint16 returns the value of an event created with NewUint16Event.
+func (e UIEvent) Uint16() (value uint16, err error) {
+	err = e.readFixed(UIResultUint16, &value)
+	return
+}
+
+// Uint32 returns the value of an event created with NewUint32Event.
+func (e UIEvent) Uint32() (value uint32, err error) {
+	err = e.readFixed(UIResultUint32, &value)
+	return
+}
+
+// Uint64 returns the value of an event created with NewUint64Event.
+func (e UIEvent) Uint64() (value uint64, err error) {
+	err = e.readFixed(UIResultUint64, &value)
+	return
+}
+
+// Uintptr returns the value of an event created with NewUintptrEvent.
+func (e UIEvent) Uintptr() (uintptr, error) {
+	var value uint64
+	err := e.readFixed(UIResultUintptr, &value)
+	return uintptr(value), err
+}
+
+// JSON decodes the data of an event created with NewJSONEvent into the value pointed to by v.
+func (e UIEvent) JSON(v interface{}) error {
+	reader, err := e.payload(UIResultJSON)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(reader).Decode(v)
+}
+
+// XML decodes the data of an event created with NewXMLEvent into the value pointed to by v.
+func (e UIEvent) XML(v interface{}) error {
+	reader, err := e.payload(UIResultXML)
+	if err != nil {
+		return err
+	}
+	return xml.NewDecoder(reader).Decode(v)
+}
+
+// Map decodes the data of an event created with NewMapEvent into the map pointed to by v.
+func (e UIEvent) Map(v interface{}) error {
+	reader, err := e.payload(UIResultMap)
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(reader).Decode(v)
+}
+
+// Slice decodes the data of an event created with NewSliceEvent into the slice pointed to by v.
+func (e UIEvent) Slice(v interface{}) error {
+	reader, err := e.payload(UIResultSlice)
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(reader).Decode(v)
+}
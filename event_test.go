@@ -0,0 +1,61 @@
+package termboxUI
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEventPayloadsRoundTrip(t *testing.T) {
+	if value, err := NewIntEvent(1, -42).Int(); value != -42 || err != nil {
+		t.Errorf("Int() = %d, %v", value, err)
+	}
+	if value, err := NewFloat64Event(1, 2.5).Float64(); value != 2.5 || err != nil {
+		t.Errorf("Float64() = %v, %v", value, err)
+	}
+	if value, err := NewRuneEvent(1, 'é').Rune(); value != 'é' || err != nil {
+		t.Errorf("Rune() = %q, %v", value, err)
+	}
+
+	event := NewStringEvent(1, "hello")
+	for i := 0; i < 2; i++ {
+		if value, err := event.StringResult(); value != "hello" || err != nil {
+			t.Errorf("StringResult() = %q, %v on read %d", value, err, i+1)
+		}
+	}
+
+	jsonEvent, err := NewJSONEvent(1, map[string]int{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]int
+	if err := jsonEvent.JSON(&decoded); err != nil || decoded["a"] != 1 {
+		t.Errorf("JSON() decoded %v, %v", decoded, err)
+	}
+
+	sliceEvent, err := NewSliceEvent(1, []string{"x", "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slice []string
+	if err := sliceEvent.Slice(&slice); err != nil || !reflect.DeepEqual(slice, []string{"x", "y"}) {
+		t.Errorf("Slice() decoded %v, %v", slice, err)
+	}
+}
+
+func TestEventAccessorChecksType(t *testing.T) {
+	_, err := NewIntEvent(1, 3).StringResult()
+	var typeError *ResultTypeError
+	if !errors.As(err, &typeError) || typeError.Expected != UIResultString || typeError.Actual != UIResultInt {
+		t.Fatalf("StringResult() on an int event returned %v", err)
+	}
+}
+
+func TestErrorEvent(t *testing.T) {
+	if value, err := NewErrorEvent(1, errors.New("disk full")).ErrorResult(); err != nil || value == nil || value.Error() != "disk full" {
+		t.Errorf("ErrorResult() = %v, %v", value, err)
+	}
+	if value, err := NewErrorEvent(1, nil).ErrorResult(); value != nil || err != nil {
+		t.Errorf("ErrorResult() of a nil error = %v, %v", value, err)
+	}
+}
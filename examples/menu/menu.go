@@ -1,8 +1,6 @@
 package main
 
 import (
-	"bytes"
-	"encoding/binary"
 	"os"
 
 	"github.com/C2FO/termboxUI"
@@ -22,15 +20,14 @@ const (
 var (
 	fgSetting termbox.Attribute
 	bgSetting termbox.Attribute
-
-	activeMenu uint16 = MainMenu
 )
 
 // This example shows a very basic menu field in action.
 // There are three options: change font color, change background color and quit.
 // Help text is supported on the options and either Ctrl+C or Esc will exit.
+// The UI is built once and run in retained mode, so the fields are changed in place instead of being rebuilt after every event.
 func main() {
-	if err := termboxUI.StartUI(buildUserInterface); err != nil {
+	if err := buildUserInterface().Start(); err != nil {
 		panic(err)
 	}
 }
@@ -38,31 +35,6 @@ func main() {
 func buildUserInterface() *termboxUI.UI {
 	newUI := new(termboxUI.UI)
 
-	newUI.CustomEvents = make(map[uint16]func(termboxUI.UIEvent))
-	newUI.CustomEvents[MenuChange] = func(event termboxUI.UIEvent) {
-		result := event.Data.Bytes()
-		rdr := bytes.NewReader(result)
-		if err := binary.Read(rdr, binary.LittleEndian, &activeMenu); err != nil {
-			panic(err)
-		}
-	}
-	newUI.CustomEvents[FgColorChange] = func(event termboxUI.UIEvent) {
-		result := event.Data.Bytes()
-		rdr := bytes.NewReader(result)
-		if err := binary.Read(rdr, binary.LittleEndian, &fgSetting); err != nil {
-			panic(err)
-		}
-	}
-	newUI.CustomEvents[BgColorChange] = func(event termboxUI.UIEvent) {
-		result := event.Data.Bytes()
-		rdr := bytes.NewReader(result)
-		if err := binary.Read(rdr, binary.LittleEndian, &bgSetting); err != nil {
-			panic(err)
-		}
-	}
-
-	screenWidth, _ := termbox.Size()
-
 	// Display the UI headline at the top by using a single string.
 	// This could also be done by using `helpbox.AddText` on each row instead of '+='
 	// The single string is used here to display the support for newline characters in the textbox
@@ -83,12 +55,50 @@ func buildUserInterface() *termboxUI.UI {
 	headline += "\n'---'            `----'                            "
 	headlineBox := termboxUI.CreateTextBox(51, 14, false, false, termboxUI.TextAlignmentDefault, termboxUI.TextAlignmentDefault, fgSetting, bgSetting)
 	headlineBox.AddText(headline)
-	newUI.AddField(headlineBox, (screenWidth-51)/2, 0, false)
+	newUI.AddField(headlineBox, 0, 0, false)
 
 	// Add the menu to the UI
-	menu := setMenu(10)
+	menu := setMenu(MainMenu, 0, 10)
 	newUI.AddField(menu, 2, 16, true)
 
+	// The screen size is only known once the UI has started, so the layout is done here.
+	newUI.OnResize = func(width, height int) {
+		newUI.MoveField(headlineBox, (width-51)/2, 0)
+		menu.Width = width - 18
+	}
+
+	newUI.CustomEvents = make(map[uint16]func(termboxUI.UIEvent))
+	newUI.CustomEvents[MenuChange] = func(event termboxUI.UIEvent) {
+		activeMenu, err := event.Uint16()
+		if err != nil {
+			panic(err)
+		}
+
+		newMenu := setMenu(activeMenu, menu.Width, menu.Height)
+		newUI.ReplaceField(menu, newMenu)
+		menu = newMenu
+	}
+	newUI.CustomEvents[FgColorChange] = func(event termboxUI.UIEvent) {
+		color, err := event.Uint16()
+		if err != nil {
+			panic(err)
+		}
+		fgSetting = termbox.Attribute(color)
+		headlineBox.Default_fg = fgSetting
+		menu.Fg = fgSetting
+		newUI.Fg = fgSetting
+	}
+	newUI.CustomEvents[BgColorChange] = func(event termboxUI.UIEvent) {
+		color, err := event.Uint16()
+		if err != nil {
+			panic(err)
+		}
+		bgSetting = termbox.Attribute(color)
+		headlineBox.Default_bg = bgSetting
+		menu.Bg = bgSetting
+		newUI.Bg = bgSetting
+	}
+
 	// Set the fg and bg attributes for all fields in the UI
 	newUI.Fg = fgSetting
 	newUI.Bg = bgSetting
@@ -97,51 +107,36 @@ func buildUserInterface() *termboxUI.UI {
 }
 
 func quit() termboxUI.UIEvent {
-	termbox.Close()
+	termboxUI.GetScreen().Close()
 	os.Exit(0)
 	return termboxUI.UIEvent{}
 }
 
-func setMenu(menuHeight int) (menu *termboxUI.Menu) {
+func setMenu(activeMenu uint16, menuWidth, menuHeight int) (menu *termboxUI.Menu) {
 	switch activeMenu {
 	case BgColorMenu:
-		return getColorMenu(menuHeight, BgColorChange)
+		return getColorMenu(menuWidth, menuHeight, BgColorChange)
 	case FgColorMenu:
-		return getColorMenu(menuHeight, FgColorChange)
+		return getColorMenu(menuWidth, menuHeight, FgColorChange)
 	default:
-		return getMainMenu(menuHeight)
+		return getMainMenu(menuWidth, menuHeight)
 	}
 }
 
-func getMainMenu(menuHeight int) (menu *termboxUI.Menu) {
-	screenWidth, _ := termbox.Size()
+func getMainMenu(menuWidth, menuHeight int) (menu *termboxUI.Menu) {
 
 	fg_color_option := termboxUI.MenuOption{
 		"Font Color",
 		"Change the font color.",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, FgColorMenu)
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = MenuChange
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(MenuChange, FgColorMenu)
 		},
 	}
 	bg_color_option := termboxUI.MenuOption{
 		"Background Color",
 		"Change the background color.",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, BgColorMenu)
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = MenuChange
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(MenuChange, BgColorMenu)
 		},
 	}
 	exit_option := termboxUI.MenuOption{
@@ -150,140 +145,76 @@ func getMainMenu(menuHeight int) (menu *termboxUI.Menu) {
 		quit,
 	}
 
-	menu = termboxUI.CreateMenu(screenWidth-18, menuHeight, "F1 - Toggle help text.", termboxUI.MenuList, false, fgSetting, bgSetting)
+	menu = termboxUI.CreateMenu(menuWidth, menuHeight, "F1 - Toggle help text.", termboxUI.MenuList, false, fgSetting, bgSetting)
 	menu.InsertMenuOption(termboxUI.MenuInsertLast, fg_color_option)
 	menu.InsertMenuOption(termboxUI.MenuInsertLast, bg_color_option)
 	menu.InsertMenuOption(termboxUI.MenuInsertLast, exit_option)
 	return menu
 }
 
-func getColorMenu(menuHeight int, colorChangeType uint16) (menu *termboxUI.Menu) {
-	screenWidth, _ := termbox.Size()
+func getColorMenu(menuWidth, menuHeight int, colorChangeType uint16) (menu *termboxUI.Menu) {
 
 	default_option := termboxUI.MenuOption{
 		"Default",
 		"Use the terminal's default color.",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, uint16(termbox.ColorDefault))
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = colorChangeType
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(colorChangeType, uint16(termbox.ColorDefault))
 		},
 	}
 	black_option := termboxUI.MenuOption{
 		"Black",
 		"Do you seriously need help text here?",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, uint16(termbox.ColorBlack))
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = colorChangeType
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(colorChangeType, uint16(termbox.ColorBlack))
 		},
 	}
 	white_option := termboxUI.MenuOption{
 		"White",
 		"Do you seriously need help text here?",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, uint16(termbox.ColorWhite))
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = colorChangeType
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(colorChangeType, uint16(termbox.ColorWhite))
 		},
 	}
 	red_option := termboxUI.MenuOption{
 		"Red",
 		"Do you seriously need help text here?",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, uint16(termbox.ColorRed))
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = colorChangeType
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(colorChangeType, uint16(termbox.ColorRed))
 		},
 	}
 	green_option := termboxUI.MenuOption{
 		"Green",
 		"Do you seriously need help text here?",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, uint16(termbox.ColorGreen))
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = colorChangeType
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(colorChangeType, uint16(termbox.ColorGreen))
 		},
 	}
 	blue_option := termboxUI.MenuOption{
 		"Blue",
 		"Do you seriously need help text here?",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, uint16(termbox.ColorBlue))
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = colorChangeType
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(colorChangeType, uint16(termbox.ColorBlue))
 		},
 	}
 	yellow_option := termboxUI.MenuOption{
 		"Yellow",
 		"Do you seriously need help text here?",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, uint16(termbox.ColorYellow))
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = colorChangeType
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(colorChangeType, uint16(termbox.ColorYellow))
 		},
 	}
 	cyan_option := termboxUI.MenuOption{
 		"Cyan",
 		"Do you seriously need help text here?",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, uint16(termbox.ColorCyan))
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = colorChangeType
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(colorChangeType, uint16(termbox.ColorCyan))
 		},
 	}
 	magenta_option := termboxUI.MenuOption{
 		"Magenta",
 		"Do you seriously need help text here?",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, uint16(termbox.ColorMagenta))
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = colorChangeType
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(colorChangeType, uint16(termbox.ColorMagenta))
 		},
 	}
 
@@ -291,18 +222,11 @@ func getColorMenu(menuHeight int, colorChangeType uint16) (menu *termboxUI.Menu)
 		"Go back",
 		"Return to the previous screen",
 		func() termboxUI.UIEvent {
-			var result = make([]byte, 2)
-			binary.LittleEndian.PutUint16(result, MainMenu)
-
-			event := termboxUI.UIEvent{}
-			event.Type = termboxUI.UIResultUint16
-			event.CustomType = MenuChange
-			event.Data = bytes.NewBuffer(result)
-			return event
+			return termboxUI.NewUint16Event(MenuChange, MainMenu)
 		},
 	}
 
-	menu = termboxUI.CreateMenu(screenWidth-18, menuHeight, "Colors", termboxUI.MenuList, false, fgSetting, bgSetting)
+	menu = termboxUI.CreateMenu(menuWidth, menuHeight, "Colors", termboxUI.MenuList, false, fgSetting, bgSetting)
 	menu.InsertMenuOption(termboxUI.MenuInsertLast, default_option)
 	menu.InsertMenuOption(termboxUI.MenuInsertLast, black_option)
 	menu.InsertMenuOption(termboxUI.MenuInsertLast, white_option)
@@ -9,6 +9,9 @@ import (
 
 var userText string = "example string"
 
+// The messages entered so far, kept outside of the UI since it is rebuilt after every message.
+var inputHistory = termboxUI.NewInputHistory(termboxUI.DefaultHistorySize)
+
 const ChangeUserText uint16 = iota
 
 func main() {
@@ -19,13 +22,13 @@ func main() {
 
 func buildUserInterface() *termboxUI.UI {
 	var x, y int
-	screenWidth, _ := termbox.Size()
+	screenWidth, _ := termboxUI.GetScreen().Size()
 
 	ui := new(termboxUI.UI)
 
 	// Headline
-	title := "Input your message in the box below.\n \nPress `Enter` to display your input all funky and whatnot.\nPress `Esc` to quit."
-	headline := termboxUI.CreateTextBox(len(title)+2, 7, false, false, termboxUI.TextAlignmentCenter, termboxUI.TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	title := "Input your message in the box below.\n \nPress `Enter` to display your input all funky and whatnot.\nPress `Up` for earlier messages.\nPress `Esc` to quit."
+	headline := termboxUI.CreateTextBox(len(title)+2, 8, false, false, termboxUI.TextAlignmentCenter, termboxUI.TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
 	headline.AddText(title)
 	x = (screenWidth - headline.Width) / 2
 	y = 1
@@ -34,11 +37,12 @@ func buildUserInterface() *termboxUI.UI {
 	// User field
 	userField := termboxUI.CreateTextBox(screenWidth-2, 3, false, false, termboxUI.TextAlignmentCenter, termboxUI.TextAlignmentCenter, termbox.ColorDefault, termbox.ColorDefault)
 	userField.AddText(funkifyString(userText))
-	y = y + 4
+	y = y + 5
 	ui.AddField(userField, 1, y, false)
 
 	// Input Box
 	inputBox := termboxUI.CreateEditBox(30, userText, ChangeUserText, termbox.ColorDefault, termbox.ColorDefault)
+	inputBox.InputHistory = inputHistory
 	x = (screenWidth - inputBox.Width) / 2
 	y = y + 3
 	ui.AddField(inputBox, x, y, true)
@@ -49,7 +53,11 @@ func buildUserInterface() *termboxUI.UI {
 	// Event Handlers
 	ui.CustomEvents = make(map[uint16]func(termboxUI.UIEvent))
 	ui.CustomEvents[ChangeUserText] = func(event termboxUI.UIEvent) {
-		userText = event.Data.String()
+		text, err := event.StringResult()
+		if err != nil {
+			panic(err)
+		}
+		userText = text
 	}
 
 	return ui
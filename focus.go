@@ -0,0 +1,141 @@
+package termboxUI
+
+import (
+	"sort"
+)
+
+//==========================//
+//          Focus           //
+//==========================//
+
+// FocusHandler is implemented by fields that can receive focus from the user.
+// A UI calls HandleFocus with 'true' when the field gains focus and 'false' when it loses it, so the field can change how it is drawn.
+// Only fields that implement FocusHandler are visited by ActionFocusNext and ActionFocusPrevious, which are bound to Tab and Shift+Tab by default.
+type FocusHandler interface {
+	HandleFocus(focused bool)
+}
+
+// FocusedField returns the field that currently receives input, or nil if no field has focus.
+func (ui *UI) FocusedField() *Field {
+	for i := range ui.fields {
+		if ui.fields[i].HasFocus {
+			return &ui.fields[i]
+		}
+	}
+	return nil
+}
+
+// SetFocus gives the input focus to the field holding the given element and takes it away from all other fields.
+// The return value is 'false' if the element is not part of the UI.
+func (ui *UI) SetFocus(element DrawHandler) bool {
+	for i := range ui.fields {
+		if ui.fields[i].Element == element {
+			ui.focusIndex(i)
+			return true
+		}
+	}
+	return false
+}
+
+// SetTabIndex changes the position of the field holding the given element in the Tab order.
+// Fields with a positive tab index are visited first in ascending order, then the fields with a tab index of 0 in the order they were added.
+// A tab index of -1 removes the field from the Tab order.
+func (ui *UI) SetTabIndex(element DrawHandler, tabIndex int) {
+	for i := range ui.fields {
+		if ui.fields[i].Element == element {
+			ui.fields[i].TabIndex = tabIndex
+		}
+	}
+}
+
+// FocusNext moves the focus to the next field in the Tab order, wrapping around after the last one.
+// The return value is 'false' if there is no other field to move to.
+func (ui *UI) FocusNext() bool {
+	return ui.moveFocus(1)
+}
+
+// FocusPrevious moves the focus to the previous field in the Tab order, wrapping around before the first one.
+// The return value is 'false' if there is no other field to move to.
+func (ui *UI) FocusPrevious() bool {
+	return ui.moveFocus(-1)
+}
+
+func (ui *UI) moveFocus(step int) bool {
+	order := ui.tabOrder()
+	if len(order) == 0 {
+		return false
+	}
+
+	position := -1
+	for i, index := range order {
+		if ui.fields[index].HasFocus {
+			position = i
+		}
+	}
+
+	if position == -1 {
+		if step > 0 {
+			ui.focusIndex(order[0])
+		} else {
+			ui.focusIndex(order[len(order)-1])
+		}
+		return true
+	}
+
+	if len(order) == 1 {
+		return false
+	}
+
+	position = (position + step + len(order)) % len(order)
+	ui.focusIndex(order[position])
+	return true
+}
+
+// The indices of the focusable fields in the order that Tab visits them.
+func (ui *UI) tabOrder() []int {
+	order := make([]int, 0, len(ui.fields))
+	for i, field := range ui.fields {
+		if _, ok := field.Element.(FocusHandler); ok && field.TabIndex >= 0 {
+			order = append(order, i)
+		}
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		tabA, tabB := ui.fields[order[a]].TabIndex, ui.fields[order[b]].TabIndex
+		if tabA == 0 || tabB == 0 {
+			return tabA != 0 && tabB == 0
+		}
+		return tabA < tabB
+	})
+	return order
+}
+
+// Move the focus to the field at the given index, notifying the fields and the UI callbacks of the change.
+func (ui *UI) focusIndex(index int) {
+	for i := range ui.fields {
+		field := &ui.fields[i]
+		if i == index || !field.HasFocus {
+			continue
+		}
+		field.HasFocus = false
+		if handler, ok := field.Element.(FocusHandler); ok {
+			handler.HandleFocus(false)
+		}
+		if ui.OnBlur != nil {
+			ui.OnBlur(field)
+		}
+	}
+
+	field := &ui.fields[index]
+	if field.HasFocus {
+		return
+	}
+	ui.Invalidate()
+	field.HasFocus = true
+	if handler, ok := field.Element.(FocusHandler); ok {
+		handler.HandleFocus(true)
+	}
+	if ui.OnFocus != nil {
+		ui.OnFocus(field)
+	}
+}
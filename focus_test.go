@@ -0,0 +1,66 @@
+package termboxUI
+
+import (
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestTabOrder(t *testing.T) {
+	SetScreen(NewSimulationScreen(40, 10))
+	defer SetScreen(nil)
+
+	ui := new(UI)
+	first := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	button := CreateButton(5, 3, "ok", termbox.ColorDefault, termbox.ColorDefault)
+	last := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	skipped := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(first, 0, 0, true)
+	ui.AddField(CreateTextBox(3, 3, false, false, 0, 0, termbox.ColorDefault, termbox.ColorDefault), 0, 3, false)
+	ui.AddField(button, 0, 6, false)
+	ui.AddField(last, 0, 9, false)
+	ui.AddField(skipped, 0, 12, false)
+	ui.SetTabIndex(last, 1)
+	ui.SetTabIndex(skipped, -1)
+
+	var focused []DrawHandler
+	ui.OnFocus = func(field *Field) { focused = append(focused, field.Element) }
+	ev := make(chan UIEvent, 1)
+
+	for i := 0; i < 3; i++ {
+		ui.HandleInput(termbox.KeyTab, 0, ev)
+	}
+	ui.HandleInput(KeyBacktab, 0, ev)
+
+	// Fields with a tab index come first, so the order is last, first, button.
+	want := []DrawHandler{button, last, first, last}
+	if len(focused) != len(want) {
+		t.Fatalf("focus moved %d times, want %d", len(focused), len(want))
+	}
+	for i := range want {
+		if focused[i] != want[i] {
+			t.Errorf("move %d focused the wrong field", i)
+		}
+	}
+	if !last.focused || first.focused || button.Active {
+		t.Error("only the focused field should be told it has focus")
+	}
+}
+
+func TestSetFocus(t *testing.T) {
+	ui := new(UI)
+	a := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	b := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(a, 0, 0, true)
+	ui.AddField(b, 0, 3, false)
+
+	var blurred DrawHandler
+	ui.OnBlur = func(field *Field) { blurred = field.Element }
+
+	if !ui.SetFocus(b) || ui.FocusedField().Element != b || blurred != a {
+		t.Error("SetFocus did not move the focus from the first field to the second")
+	}
+	if ui.SetFocus(CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)) {
+		t.Error("SetFocus accepted an element that is not part of the UI")
+	}
+}
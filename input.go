@@ -0,0 +1,64 @@
+package termboxUI
+
+import (
+	"github.com/nsf/termbox-go"
+)
+
+//==========================//
+//       Input Reader       //
+//==========================//
+
+// The number of input events that can wait while the UI is busy handling something else.
+const inputBufferSize = 64
+
+// An inputReader is the one goroutine that reads input from the screen while a UI runs.
+// Events are queued on a buffered channel, so input is never lost while the event loop is busy with other work.
+type inputReader struct {
+	screen Screen
+	events chan termbox.Event
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// Start reading input from the screen.
+func startInputReader(screen Screen) *inputReader {
+	reader := &inputReader{
+		screen: screen,
+		events: make(chan termbox.Event, inputBufferSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go reader.read()
+	return reader
+}
+
+func (r *inputReader) read() {
+	defer close(r.done)
+
+	for {
+		ev := r.screen.PollEvent()
+
+		if ev.Type == termbox.EventInterrupt {
+			select {
+			case <-r.stop:
+				return
+			default:
+				continue
+			}
+		}
+
+		// Once the reader is stopping, events are dropped until the interrupt from Close arrives.
+		select {
+		case r.events <- ev:
+		case <-r.stop:
+		}
+	}
+}
+
+// Close stops the reader and waits for its goroutine to finish.
+// The screen is interrupted so that the reader does not stay blocked waiting for input.
+func (r *inputReader) Close() {
+	close(r.stop)
+	r.screen.Interrupt()
+	<-r.done
+}
@@ -0,0 +1,266 @@
+package termboxUI
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nsf/termbox-go"
+)
+
+//============================//
+//       Input History        //
+//----------------------------//
+
+// The number of entries an input history keeps unless MaxEntries is changed.
+const DefaultHistorySize = 500
+
+// An input history remembers the text submitted from an edit box, so it can be recalled like the history of a shell.
+// It lives apart from the edit box so that it survives the edit box being built again, and can be shared between edit boxes.
+// Empty text is not added, and text that is already in the history moves to the end instead of being added twice.
+// With a Path, the history is saved to that file after every addition. See LoadInputHistory.
+type InputHistory struct {
+	MaxEntries int // 0 for no limit
+	Path       string
+
+	entries []string // the oldest first
+}
+
+// Creates a new, empty input history that is kept in memory.
+func NewInputHistory(maxEntries int) *InputHistory {
+	return &InputHistory{MaxEntries: maxEntries}
+}
+
+// LoadInputHistory reads the history saved at 'path', one entry per line, and keeps saving to it from then on.
+// A file that does not exist yet is not an error; it is created by the first Add.
+func LoadInputHistory(path string, maxEntries int) (*InputHistory, error) {
+	history := &InputHistory{MaxEntries: maxEntries, Path: path}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		history.add(scanner.Text())
+	}
+	return history, scanner.Err()
+}
+
+// Entries returns a copy of the history, the oldest entry first.
+func (h *InputHistory) Entries() []string {
+	return append([]string(nil), h.entries...)
+}
+
+// Add puts an entry at the end of the history, and saves the history if it has a Path.
+func (h *InputHistory) Add(entry string) error {
+	if !h.add(entry) || h.Path == "" {
+		return nil
+	}
+	return h.Save()
+}
+
+// Add an entry without saving. The return value is 'false' if nothing changed.
+func (h *InputHistory) add(entry string) bool {
+	// Entries are saved one per line.
+	entry = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(entry)
+	if strings.TrimSpace(entry) == "" {
+		return false
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry {
+		return false
+	}
+
+	for i, existing := range h.entries {
+		if existing == entry {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	h.entries = append(h.entries, entry)
+	if h.MaxEntries > 0 && len(h.entries) > h.MaxEntries {
+		h.entries = append(h.entries[:0], h.entries[len(h.entries)-h.MaxEntries:]...)
+	}
+	return true
+}
+
+// Save writes the history to its Path. The file is replaced as a whole so that a failed write leaves the old one in place.
+// Only the owner can read it, since a history can hold anything that was typed.
+func (h *InputHistory) Save() error {
+	file, err := os.CreateTemp(filepath.Dir(h.Path), filepath.Base(h.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	writer := bufio.NewWriter(file)
+	for _, entry := range h.entries {
+		writer.WriteString(entry)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), h.Path)
+}
+
+//============================//
+//   Edit Box History Recall  //
+//----------------------------//
+
+// Up and down step through the InputHistory of an edit box, and Ctrl+R searches back through it like it does in bash.
+// While searching, typing narrows the search, Ctrl+R moves on to an older match, and 'Esc' or Ctrl+G puts back the
+// text from before the search. Any other key takes the match and then does what it normally does, so 'Enter' submits it.
+
+// The state of stepping through the history of an edit box.
+type historyRecall struct {
+	active bool
+	index  int    // the entry shown in the edit box
+	draft  []rune // the text that was being edited before the history was recalled
+}
+
+// The state of a reverse search through the history of an edit box.
+type historySearch struct {
+	active bool
+	query  []rune
+	index  int  // the entry that matches
+	failed bool // nothing older matches the query
+	before editState
+}
+
+// Add the text of the edit box to its history when it is submitted, and stop stepping through the history.
+// A history that can't be saved is reported as an error event with the custom type of the edit box, after the text.
+func (eb *EditBox) remember(ev chan UIEvent) {
+	eb.recall = historyRecall{}
+	if eb.InputHistory == nil {
+		return
+	}
+	if err := eb.InputHistory.Add(string(eb.Value)); err != nil {
+		eb.reportError(err, ev)
+	}
+}
+
+// Step through the history, towards older entries for a negative 'delta'.
+// Stepping past the newest entry brings back the text that was being edited.
+func (eb *EditBox) recallHistory(delta int) {
+	if eb.InputHistory == nil {
+		return
+	}
+	entries := eb.InputHistory.entries
+
+	if !eb.recall.active {
+		if delta > 0 || len(entries) == 0 {
+			return
+		}
+		eb.recall = historyRecall{active: true, index: len(entries), draft: eb.Value}
+	}
+
+	index := clampIndex(eb.recall.index+delta, len(entries))
+	eb.recall.index = index
+	eb.Edit(func() {
+		if index == len(entries) {
+			eb.Value = eb.recall.draft
+			eb.recall = historyRecall{}
+		} else {
+			eb.Value = []rune(entries[index])
+		}
+		eb.moveCursor(len(eb.Value))
+	})
+}
+
+// Start a reverse search through the history.
+func (eb *EditBox) startHistorySearch() {
+	if eb.InputHistory == nil {
+		return
+	}
+	eb.search = historySearch{active: true, index: len(eb.InputHistory.entries), before: eb.state()}
+	eb.selecting = false
+}
+
+// Find the newest entry at or before 'from' that holds the query, and show it with the cursor on the match.
+func (eb *EditBox) findInHistory(from int) {
+	entries := eb.InputHistory.entries
+	query := string(eb.search.query)
+
+	for i := from; i >= 0; i-- {
+		if i >= len(entries) {
+			continue
+		}
+		if at := strings.Index(entries[i], query); at >= 0 {
+			eb.search.index = i
+			eb.search.failed = false
+			eb.Value = []rune(entries[i])
+			eb.CursorIndex = len([]rune(entries[i][:at]))
+			return
+		}
+	}
+	eb.search.failed = true
+}
+
+// Handle a key while searching the history. The return value is 'false' when the key ends the search
+// and should then be handled as usual.
+func (eb *EditBox) handleHistorySearchKey(binding KeyBinding) bool {
+	key, ch := binding.Key, binding.Ch
+	if binding.Mod != 0 {
+		eb.acceptHistorySearch()
+		return false
+	}
+
+	switch key {
+	case termbox.KeyCtrlR:
+		eb.findInHistory(eb.search.index - 1)
+	case termbox.KeyEsc, termbox.KeyCtrlG:
+		eb.restore(eb.search.before)
+		eb.search = historySearch{}
+	case termbox.KeyBackspace, termbox.KeyBackspace2:
+		if len(eb.search.query) > 0 {
+			eb.search.query = eb.search.query[:len(eb.search.query)-1]
+			eb.findInHistory(len(eb.InputHistory.entries) - 1)
+		}
+	case termbox.KeySpace:
+		eb.search.query = append(eb.search.query, ' ')
+		eb.findInHistory(eb.search.index)
+	default:
+		if key == 0 && ch != 0 {
+			eb.search.query = append(eb.search.query, ch)
+			eb.findInHistory(eb.search.index)
+			break
+		}
+
+		eb.acceptHistorySearch()
+		return false
+	}
+	return true
+}
+
+// End the search, keeping the match as a single edit that can be undone.
+func (eb *EditBox) acceptHistorySearch() {
+	before := eb.search.before
+	eb.search = historySearch{}
+	eb.recall = historyRecall{}
+	if string(before.value) != string(eb.Value) {
+		eb.history.record(before, editOther, eb.UndoDepth)
+	}
+}
+
+// The prompt shown in front of the text.
+func (eb *EditBox) prompt() string {
+	switch {
+	case !eb.search.active:
+		return "/> "
+	case eb.search.failed:
+		return "(failed reverse-i-search)`" + string(eb.search.query) + "': "
+	default:
+		return "(reverse-i-search)`" + string(eb.search.query) + "': "
+	}
+}
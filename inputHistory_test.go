@@ -0,0 +1,100 @@
+package termboxUI
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestInputHistoryAdd(t *testing.T) {
+	history := NewInputHistory(3)
+	for _, entry := range []string{"a", "b", " ", "a", "a", "c\nd", "e"} {
+		history.Add(entry)
+	}
+	if got, want := history.Entries(), []string{"a", "c d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entries are %q, want %q", got, want)
+	}
+}
+
+func TestInputHistorySaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	history, err := LoadInputHistory(path, 10)
+	if err != nil {
+		t.Fatalf("loading a missing history: %v", err)
+	}
+	history.Add("first")
+	history.Add("second")
+
+	loaded, err := LoadInputHistory(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := loaded.Entries(), []string{"first", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded %q, want %q", got, want)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("the history file is %v, %v, want it only readable by its owner", info, err)
+	}
+}
+
+func TestSubmitReportsASaveErrorWithoutBlocking(t *testing.T) {
+	eb := CreateEditBox(20, "text", 5, termbox.ColorDefault, termbox.ColorDefault)
+	eb.InputHistory = &InputHistory{Path: filepath.Join(t.TempDir(), "missing", "history")}
+
+	// The UI gives fields a channel with room for one event.
+	ev := make(chan UIEvent, 1)
+	done := make(chan struct{})
+	go func() {
+		EditSubmit(eb, ev)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("submitting blocked on the save error")
+	}
+
+	if text, _ := (<-ev).StringResult(); text != "text" {
+		t.Errorf("submitted %q", text)
+	}
+	select {
+	case event := <-ev:
+		if err, _ := event.ErrorResult(); err == nil || event.CustomType != 5 {
+			t.Errorf("got %v with custom type %d, want the save error", err, event.CustomType)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the save error was not reported")
+	}
+}
+
+func TestHistoryRecallAndSearch(t *testing.T) {
+	eb := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	eb.InputHistory = NewInputHistory(0)
+	for _, entry := range []string{"make test", "git status", "make build"} {
+		eb.InputHistory.Add(entry)
+	}
+	ev := make(chan UIEvent, 1)
+
+	pressKeys(eb, ev, "dr", KeyBinding{Key: termbox.KeyArrowUp}, KeyBinding{Key: termbox.KeyArrowUp})
+	if string(eb.Value) != "git status" {
+		t.Errorf("two steps back show %q", string(eb.Value))
+	}
+	pressKeys(eb, ev, KeyBinding{Key: termbox.KeyArrowDown}, KeyBinding{Key: termbox.KeyArrowDown})
+	if string(eb.Value) != "dr" {
+		t.Errorf("stepping past the newest entry shows %q, want the draft", string(eb.Value))
+	}
+
+	pressKeys(eb, ev, KeyBinding{Key: termbox.KeyCtrlR}, "make", KeyBinding{Key: termbox.KeyCtrlR})
+	if string(eb.Value) != "make test" {
+		t.Errorf("searching for an older match shows %q", string(eb.Value))
+	}
+	pressKeys(eb, ev, KeyBinding{Key: termbox.KeyEsc})
+	if string(eb.Value) != "dr" {
+		t.Errorf("Esc left %q, want the text from before the search", string(eb.Value))
+	}
+}
@@ -0,0 +1,69 @@
+package termboxUI
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestInputReaderKeepsEventsInOrder(t *testing.T) {
+	screen := NewSimulationScreen(10, 2)
+	reader := startInputReader(screen)
+	defer reader.Close()
+
+	screen.InjectKeys("abc")
+	for _, want := range "abc" {
+		select {
+		case ev := <-reader.events:
+			if ev.Ch != want {
+				t.Errorf("got %q, want %q", ev.Ch, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("the reader did not pass the input on")
+		}
+	}
+}
+
+func TestInputReaderCloseStopsWhileWaiting(t *testing.T) {
+	screen := NewSimulationScreen(10, 2)
+	reader := startInputReader(screen)
+
+	// Fill the queue so that the reader is blocked handing over an event.
+	for i := 0; i < inputBufferSize+1; i++ {
+		screen.InjectKey(0, 'x')
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		reader.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not stop the reader")
+	}
+}
+
+func TestRunDoesNotLeakGoroutines(t *testing.T) {
+	SetScreen(NewSimulationScreen(20, 5))
+	defer SetScreen(nil)
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		ui := new(UI)
+		ui.AddField(CreateEditBox(10, "", 0, termbox.ColorDefault, termbox.ColorDefault), 0, 0, true)
+		cancel()
+		ui.Run(ctx)
+	}
+
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines are left over from running the UI, there were %d before", runtime.NumGoroutine(), before)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package termboxUI
+
+import (
+	"github.com/nsf/termbox-go"
+)
+
+//==========================//
+//       Key Bindings       //
+//==========================//
+
+// KeyBinding identifies a single key press the same way a termbox event does:
+// Key is set for special keys, Ch is set with a Key of 0 for characters, and Mod holds modifiers such as termbox.ModAlt.
+type KeyBinding struct {
+	Key termbox.Key
+	Ch  rune
+	Mod termbox.Modifier
+}
+
+// KeyClaimer is implemented by fields that at times need keys that are otherwise bound to actions, such as 'Esc' while a prompt is open.
+// When ClaimsKey returns 'true' for the focused field, the key goes straight to the field without looking up any bindings.
+type KeyClaimer interface {
+	ClaimsKey(key termbox.Key, ch rune) bool
+}
+
+// KeyBindingHandler is implemented by fields that tell keys apart by their modifiers, such as Alt+B from a plain 'b'.
+// HandleKeyBinding is called in place of HandleKey when the field has focus.
+type KeyBindingHandler interface {
+	HandleKeyBinding(binding KeyBinding, event chan UIEvent) bool
+}
+
+// Send a key press to an element, along with its modifiers if the element takes them.
+func sendKey(element DrawHandler, binding KeyBinding, event chan UIEvent) bool {
+	if handler, ok := element.(KeyBindingHandler); ok {
+		return handler.HandleKeyBinding(binding, event)
+	}
+	return element.HandleKey(binding.Key, binding.Ch, event)
+}
+
+// Action is run when the key it is bound to is pressed.
+// It returns 'false' if the key was not used, in which case the key continues on to the focused field.
+type Action func(ui *UI) bool
+
+// These are the built-in actions that can be bound to keys.
+var (
+	// ActionQuit stops the UI event loop.
+	ActionQuit Action = func(ui *UI) bool {
+		ui.Quit()
+		return true
+	}
+
+	// ActionRedraw repaints the whole screen, which fixes any output that was garbled by other programs.
+	ActionRedraw Action = func(ui *UI) bool {
+		activeScreen.Sync()
+		ui.Invalidate()
+		return true
+	}
+
+	// ActionFocusNext moves the focus to the next field in the Tab order.
+	ActionFocusNext Action = (*UI).FocusNext
+
+	// ActionFocusPrevious moves the focus to the previous field in the Tab order.
+	ActionFocusPrevious Action = (*UI).FocusPrevious
+)
+
+// CommandAction creates an action that runs a command the same way a menu option does.
+// The command runs on its own goroutine and the resulting event is delivered to the UI's event handlers.
+func CommandAction(command func() UIEvent) Action {
+	return func(ui *UI) bool {
+		if ui.results == nil {
+			return false
+		}
+		go func(results chan UIEvent) {
+			results <- command()
+		}(ui.results)
+		return true
+	}
+}
+
+// DefaultKeyMap returns the bindings used by a UI that has no KeyMap of its own:
+// Esc and Ctrl+C quit, Tab and Shift+Tab move the focus and Ctrl+L redraws the screen.
+func DefaultKeyMap() map[KeyBinding]Action {
+	return map[KeyBinding]Action{
+		{Key: termbox.KeyEsc}:   ActionQuit,
+		{Key: termbox.KeyCtrlC}: ActionQuit,
+		{Key: termbox.KeyTab}:   ActionFocusNext,
+		{Key: KeyBacktab}:       ActionFocusPrevious,
+		{Key: termbox.KeyCtrlL}: ActionRedraw,
+	}
+}
+
+// Bind adds a global key binding to the UI, replacing any action already bound to that key.
+// If the UI has no KeyMap yet, it starts out with the default bindings.
+func (ui *UI) Bind(binding KeyBinding, action Action) {
+	if ui.KeyMap == nil {
+		ui.KeyMap = DefaultKeyMap()
+	}
+	ui.KeyMap[binding] = action
+}
+
+// Unbind removes a global key binding from the UI so that the key goes to the focused field instead.
+// If the UI has no KeyMap yet, it starts out with the default bindings.
+func (ui *UI) Unbind(binding KeyBinding) {
+	if ui.KeyMap == nil {
+		ui.KeyMap = DefaultKeyMap()
+	}
+	delete(ui.KeyMap, binding)
+}
+
+// DisableQuitKeys removes the default Esc and Ctrl+C quit bindings, leaving the keys for the fields.
+// The UI can still be stopped with Quit or by binding ActionQuit to another key.
+func (ui *UI) DisableQuitKeys() {
+	ui.Unbind(KeyBinding{Key: termbox.KeyEsc})
+	ui.Unbind(KeyBinding{Key: termbox.KeyCtrlC})
+}
+
+// BindField adds a key binding that only applies while the field holding the given element has focus.
+// It takes priority over the global bindings. A nil action sends the key straight to the element, skipping the global bindings.
+// The return value is 'false' if the element is not part of the UI.
+func (ui *UI) BindField(element DrawHandler, binding KeyBinding, action Action) bool {
+	for i := range ui.fields {
+		field := &ui.fields[i]
+		if field.Element != element {
+			continue
+		}
+		if field.KeyMap == nil {
+			field.KeyMap = make(map[KeyBinding]Action)
+		}
+		field.KeyMap[binding] = action
+		return true
+	}
+	return false
+}
+
+// Quit stops the UI event loop once the current event has been handled.
+func (ui *UI) Quit() {
+	ui.quitting = true
+}
+
+// Look up the action for a key press, first in the focused field's bindings and then in the global ones.
+// A nil action is returned when nothing is bound or when a field binding asks for the key to skip the global bindings.
+func (ui *UI) lookupBinding(field *Field, binding KeyBinding) Action {
+	if field != nil {
+		if action, ok := field.KeyMap[binding]; ok {
+			return action
+		}
+	}
+
+	keyMap := ui.KeyMap
+	if keyMap == nil {
+		keyMap = defaultKeyMap
+	}
+	return keyMap[binding]
+}
+
+// The bindings used when the UI does not define a KeyMap.
+var defaultKeyMap = DefaultKeyMap()
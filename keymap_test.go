@@ -0,0 +1,105 @@
+package termboxUI
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestEscQuitsWithTheDefaultKeyMap(t *testing.T) {
+	screen := NewSimulationScreen(40, 10)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	ui := new(UI)
+	ui.AddField(CreateEditBox(20, "", 1, termbox.ColorDefault, termbox.ColorDefault), 0, 0, true)
+
+	done := make(chan error)
+	go func() { done <- ui.Run(context.Background()) }()
+	screen.InjectKey(termbox.KeyEsc, 0)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Esc did not quit the UI")
+	}
+	if mode := screen.InputMode(); mode&termbox.InputEsc == 0 || mode&termbox.InputAlt != 0 {
+		t.Errorf("input mode is %d, want Esc to be reported as a key", mode)
+	}
+}
+
+// Run the UI on the screen and wait for it to quit.
+func runUntilQuit(t *testing.T, ui *UI, screen *SimulationScreen, input ...termbox.Event) {
+	t.Helper()
+	ui.Screen = screen
+	done := make(chan error)
+	go func() { done <- ui.Run(context.Background()) }()
+	for _, ev := range input {
+		screen.InjectEvent(ev)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the UI did not quit")
+	}
+}
+
+func TestFieldBindingsTakePriority(t *testing.T) {
+	ui := new(UI)
+	first := CreateEditBox(20, "", 1, termbox.ColorDefault, termbox.ColorDefault)
+	second := CreateEditBox(20, "", 2, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(first, 0, 0, true)
+	ui.AddField(second, 0, 3, false)
+	ui.BindField(first, KeyBinding{Ch: 'q'}, ActionQuit)
+	ui.BindField(second, KeyBinding{Key: termbox.KeyEsc}, nil)
+
+	runUntilQuit(t, ui, NewSimulationScreen(40, 10),
+		KeyEvent(termbox.KeyTab, 0),
+		KeyEvent(termbox.KeyEsc, 0), // goes to the second field instead of quitting
+		KeyEvent(0, 'q'),
+		KeyEvent(KeyBacktab, 0),
+		KeyEvent(0, 'q'),
+	)
+	if string(first.Value) != "" || string(second.Value) != "q" {
+		t.Errorf("the fields hold %q and %q", string(first.Value), string(second.Value))
+	}
+}
+
+func TestDisableQuitKeysAndAltBindings(t *testing.T) {
+	ui := new(UI)
+	eb := CreateEditBox(20, "", 1, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(eb, 0, 0, true)
+	ui.DisableQuitKeys()
+	ui.Bind(KeyBinding{Ch: 'q', Mod: termbox.ModAlt}, ActionQuit)
+
+	runUntilQuit(t, ui, NewSimulationScreen(40, 10),
+		KeyEvent(termbox.KeyEsc, 0),
+		KeyEvent(termbox.KeyCtrlC, 0),
+		KeyEvent(0, 'q'),
+		termbox.Event{Type: termbox.EventKey, Ch: 'q', Mod: termbox.ModAlt},
+	)
+	if string(eb.Value) != "q" {
+		t.Errorf("the field holds %q, want only the plain q", string(eb.Value))
+	}
+}
+
+func TestCommandActionDeliversItsEvent(t *testing.T) {
+	ui := new(UI)
+	ui.AddField(CreateEditBox(20, "", 1, termbox.ColorDefault, termbox.ColorDefault), 0, 0, true)
+	ui.Bind(KeyBinding{Key: termbox.KeyCtrlR}, CommandAction(func() UIEvent {
+		return NewStringEvent(5, "ran")
+	}))
+	var got string
+	ui.CustomEvents = map[uint16]func(UIEvent){5: func(event UIEvent) {
+		got, _ = event.StringResult()
+		ui.Quit()
+	}}
+
+	runUntilQuit(t, ui, NewSimulationScreen(40, 10), KeyEvent(termbox.KeyCtrlR, 0))
+	if got != "ran" {
+		t.Errorf("the command's event held %q", got)
+	}
+}
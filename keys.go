@@ -0,0 +1,97 @@
+package termboxUI
+
+import (
+	"unicode/utf8"
+
+	"github.com/nsf/termbox-go"
+)
+
+//==========================//
+//      Extended Keys       //
+//==========================//
+
+// Keys that termbox does not report on its own.
+// TermboxScreen produces them from the escape sequences listed in extendedKeySequences, and other screens may produce them directly.
+// They are numbered well below the termbox function, arrow and mouse keys so that the two never overlap.
+const (
+	KeyBacktab   termbox.Key = 0xFFFF - 64 - iota // Shift+Tab
+	KeyCtrlEnter                                  // Ctrl+Enter, for terminals that report it. Most send KeyCtrlJ instead.
+	KeyShiftArrowLeft
+	KeyShiftArrowRight
+	KeyShiftHome
+	KeyShiftEnd
+	KeyCtrlShiftArrowLeft
+	KeyCtrlShiftArrowRight
+	KeyPasteStart // the start of text pasted into a terminal with bracketed paste turned on
+	KeyPasteEnd   // the end of pasted text
+)
+
+// The escape sequences sent by common terminals for the extended keys.
+var extendedKeySequences = map[string]termbox.Key{
+	"\x1b[Z":        KeyBacktab,
+	"\x1b[27;5;13~": KeyCtrlEnter, // xterm with modifyOtherKeys
+	"\x1b[13;5u":    KeyCtrlEnter, // CSI u, as sent by kitty and others
+	"\x1b[1;2D":     KeyShiftArrowLeft,
+	"\x1b[1;2C":     KeyShiftArrowRight,
+	"\x1b[1;2H":     KeyShiftHome,
+	"\x1b[1;2F":     KeyShiftEnd,
+	"\x1b[1;6D":     KeyCtrlShiftArrowLeft,
+	"\x1b[1;6C":     KeyCtrlShiftArrowRight,
+	"\x1b[200~":     KeyPasteStart,
+	"\x1b[201~":     KeyPasteEnd,
+}
+
+// Look for an extended key sequence at the start of data.
+// The key and the number of bytes in its sequence are returned, or a length of 0 if there is no match.
+func parseExtendedKey(data []byte) (termbox.Key, int) {
+	for sequence, key := range extendedKeySequences {
+		if len(data) >= len(sequence) && string(data[:len(sequence)]) == sequence {
+			return key, len(sequence)
+		}
+	}
+	return 0, 0
+}
+
+// Whether data stops partway through an escape sequence or a UTF-8 character, so that more input is needed to parse it.
+// A lone escape counts as well, as the key that makes it Alt+key may still follow.
+func incompleteInput(data []byte) bool {
+	if len(data) == 0 || data[0] != '\x1b' {
+		return !utf8.FullRune(data)
+	}
+	if len(data) < 2 {
+		return true
+	}
+
+	if data[1] == '[' || data[1] == 'O' {
+		return escapeSequenceLength(data) == 0
+	}
+	return !utf8.FullRune(data[1:])
+}
+
+// The length of the control sequence (ESC [) or SS3 sequence (ESC O) at the start of data,
+// or 0 if data does not start with a whole one.
+func escapeSequenceLength(data []byte) int {
+	if len(data) < 3 || data[0] != '\x1b' {
+		return 0
+	}
+
+	switch data[1] {
+	case '[':
+		// A mouse report has three bytes after "\x1b[M".
+		if data[2] == 'M' {
+			if len(data) < 6 {
+				return 0
+			}
+			return 6
+		}
+		// Any other control sequence ends with a byte from '@' to '~'.
+		for i, b := range data[2:] {
+			if b >= '@' && b <= '~' {
+				return i + 3
+			}
+		}
+	case 'O':
+		return 3
+	}
+	return 0
+}
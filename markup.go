@@ -0,0 +1,136 @@
+package termboxUI
+
+import (
+	"strings"
+
+	"github.com/nsf/termbox-go"
+)
+
+//======================================================//
+// Markup
+//======================================================//
+
+// Text given to TextBox.AddMarkup, or to menus, table cells, buttons and popups with their Markup field set,
+// can be styled with tags:
+//
+//	[fg:bg:flags]  sets the style of the text that follows, e.g. "[red::b]error[-]"
+//	[-]            goes back to the default style
+//	[[             is a literal '['
+//
+// Colors are named: default, black, red, green, yellow, blue, magenta, cyan or white. Flags are any of
+// 'b' for bold, 'u' for underline and 'r' for reverse. A part that is left empty keeps its current setting,
+// and a part set to '-' goes back to its default. A '[' that doesn't start a valid tag is shown as it is.
+// Text given to TextBox.AddText, or to fields without Markup set, is always shown as it is.
+
+var markupColors = map[string]termbox.Attribute{
+	"default": termbox.ColorDefault,
+	"black":   termbox.ColorBlack,
+	"red":     termbox.ColorRed,
+	"green":   termbox.ColorGreen,
+	"yellow":  termbox.ColorYellow,
+	"blue":    termbox.ColorBlue,
+	"magenta": termbox.ColorMagenta,
+	"cyan":    termbox.ColorCyan,
+	"white":   termbox.ColorWhite,
+}
+
+// ParseMarkup converts marked-up text into styled spans.
+func ParseMarkup(text string) []Span {
+	var spans []Span
+	var run strings.Builder
+	var style Span
+
+	for len(text) > 0 {
+		open := strings.IndexByte(text, '[')
+		if open < 0 {
+			run.WriteString(text)
+			break
+		}
+		run.WriteString(text[:open])
+		text = text[open:]
+
+		if strings.HasPrefix(text, "[[") {
+			run.WriteByte('[')
+			text = text[2:]
+			continue
+		}
+
+		end := strings.IndexByte(text, ']')
+		if end < 0 {
+			run.WriteString(text)
+			break
+		}
+		next, ok := applyMarkupTag(text[1:end], style)
+		if !ok {
+			run.WriteByte('[')
+			text = text[1:]
+			continue
+		}
+
+		if run.Len() > 0 {
+			spans = append(spans, style.withText(run.String()))
+			run.Reset()
+		}
+		style = next
+		text = text[end+1:]
+	}
+
+	if run.Len() > 0 {
+		spans = append(spans, style.withText(run.String()))
+	}
+	return spans
+}
+
+// MarkupWidth returns the number of terminal columns needed to display marked-up text, leaving out the tags.
+func MarkupWidth(text string) int {
+	return spansWidth(ParseMarkup(text))
+}
+
+// EscapeMarkup makes text show up as it is when it is used as markup, such as text typed in by the user.
+func EscapeMarkup(text string) string {
+	return strings.Replace(text, "[", "[[", -1)
+}
+
+// Apply the contents of a tag to a style. 'ok' is false if the tag isn't valid.
+func applyMarkupTag(tag string, style Span) (next Span, ok bool) {
+	if tag == "-" {
+		return Span{}, true
+	}
+
+	parts := strings.Split(tag, ":")
+	if len(parts) > 3 || strings.Trim(tag, ":") == "" {
+		return style, false
+	}
+
+	colors := []*termbox.Attribute{&style.Fg, &style.Bg}
+	for i := 0; i < len(parts) && i < 2; i++ {
+		switch parts[i] {
+		case "":
+		case "-":
+			*colors[i] = termbox.ColorDefault
+		default:
+			color, found := markupColors[parts[i]]
+			if !found {
+				return style, false
+			}
+			*colors[i] = color
+		}
+	}
+
+	if len(parts) == 3 {
+		switch flags := parts[2]; flags {
+		case "":
+		case "-":
+			style.Bold, style.Underline, style.Reverse = false, false, false
+		default:
+			if strings.Trim(flags, "bur") != "" {
+				return style, false
+			}
+			style.Bold = strings.ContainsRune(flags, 'b')
+			style.Underline = strings.ContainsRune(flags, 'u')
+			style.Reverse = strings.ContainsRune(flags, 'r')
+		}
+	}
+
+	return style, true
+}
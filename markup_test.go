@@ -0,0 +1,66 @@
+package termboxUI
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestParseMarkup(t *testing.T) {
+	got := ParseMarkup("[red::b]error[-] at [[x] and [nope]")
+	want := []Span{
+		{Text: "error", Fg: termbox.ColorRed, Bold: true},
+		{Text: " at [x] and [nope]"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMarkup = %+v, want %+v", got, want)
+	}
+
+	got = ParseMarkup("[:blue:u]a[green]b[::-]c")
+	want = []Span{
+		{Text: "a", Bg: termbox.ColorBlue, Underline: true},
+		{Text: "b", Fg: termbox.ColorGreen, Bg: termbox.ColorBlue, Underline: true},
+		{Text: "c", Fg: termbox.ColorGreen, Bg: termbox.ColorBlue},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMarkup = %+v, want %+v", got, want)
+	}
+}
+
+func TestMarkupWidthAndEscape(t *testing.T) {
+	if width := MarkupWidth("[yellow]warn[-] 日本"); width != 9 {
+		t.Errorf("MarkupWidth = %d, want 9", width)
+	}
+	text := "[red] is not a tag"
+	if got := spansText(ParseMarkup(EscapeMarkup(text))); got != text {
+		t.Errorf("escaped markup shows %q, want %q", got, text)
+	}
+}
+
+func TestMarkupIsOptIn(t *testing.T) {
+	screen := NewSimulationScreen(20, 4)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	plain := CreateTextBox(20, 1, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	plain.AddText("[red]x")
+	plain.Draw(0, 0)
+	if got := trimmedLine(screen, 0); got != "[red]x" {
+		t.Errorf("AddText shows %q", got)
+	}
+
+	marked := CreateTextBox(20, 1, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	marked.AddMarkup("[red]x")
+	marked.Draw(0, 1)
+	if got := trimmedLine(screen, 1); got != "x" || screen.Cell(0, 1).Fg != termbox.ColorRed {
+		t.Errorf("AddMarkup shows %q in %d", got, screen.Cell(0, 1).Fg)
+	}
+
+	button := CreateButton(10, 3, "[b]", termbox.ColorDefault, termbox.ColorDefault)
+	button.Draw(10, 0)
+	if got := screen.Line(1); !strings.Contains(got, "│[b]") {
+		t.Errorf("button shows %q", got)
+	}
+}
@@ -41,6 +41,7 @@ const (
 // A Menu is a fully-featured menu for the termbox-go platform!
 // This consists of an array of options, each one capable executing its own command to handle user interaction
 // A user can either use the arrow keys or a number to highlight a menu option. Use the 'enter' or 'return' key to select that option and execute its command.
+// With Markup set, the header and the option titles can be styled with markup tags, see ParseMarkup.
 type Menu struct {
 	Width       int
 	Height      int
@@ -49,19 +50,21 @@ type Menu struct {
 	DrawHelpBox bool
 	Fg          termbox.Attribute
 	Bg          termbox.Attribute
+	Markup      bool
 
 	Options []MenuOption
 
 	activeIndex int
 	menuTop     int
 	menuBottom  int
+	focused     bool
 }
 
 // This creates an instance of a new Menu.
 // If drawHelpBox is true then the F1 key will display the description of the menu option using a pop up at the bottom of the screen.
 func CreateMenu(width, height int, header string, mode MenuMode, drawHelpBox bool, fg, bg termbox.Attribute) *Menu {
 	options := make([]MenuOption, 0)
-	return &Menu{width, height, header, mode, drawHelpBox, fg, bg, options, 0, 0, height}
+	return &Menu{width, height, header, mode, drawHelpBox, fg, bg, false, options, 0, 0, height, false}
 }
 
 // this adds a new menu option
@@ -98,12 +101,18 @@ func (m *Menu) ReplaceMenuOption(index int, newOption MenuOption) {
 
 // Draws the menu to the terminal at the specified indices.
 func (m *Menu) Draw(x, y int) {
-	cols := 1
-
 	//Draw the menu Title
 	if len(m.Header) > 0 {
-		titleBox := CreateTextBox(m.Width, 1, false, false, TextAlignmentCenter, TextAlignmentDefault, m.Fg, m.Bg)
-		titleBox.AddText(m.Header)
+		titleFg := m.Fg
+		if m.focused {
+			titleFg |= termbox.AttrBold
+		}
+		titleBox := CreateTextBox(m.Width, 1, false, false, TextAlignmentCenter, TextAlignmentDefault, titleFg, m.Bg)
+		header := []Span{{Text: m.Header}}
+		if m.Markup {
+			header = ParseMarkup(m.Header)
+		}
+		titleBox.AddStyledText(truncateSpans(header, m.Width)...)
 		titleBox.Draw(x, y)
 		DrawHorizontalLine(x, y+1, m.Width, m.Fg, m.Bg)
 		y += 3
@@ -113,14 +122,10 @@ func (m *Menu) Draw(x, y int) {
 		m.menuBottom = len(m.Options)
 	}
 
-	rows := m.menuBottom
-
-	if m.Mode == MenuGrid {
-		cols = 2
-		rows = m.menuBottom/cols + 1
-	}
+	cols, rows, _ := m.grid()
 
 	table := CreateTable(m.Width, m.menuBottom, cols, rows, nil, nil, false, true, m.Fg, m.Bg)
+	table.Markup = m.Markup
 	for c := 0; c < cols; c++ {
 		for r := m.menuTop; r < rows; r++ {
 			index := getIndexFromCoordinates(rows, c, r)
@@ -143,6 +148,54 @@ func (m *Menu) Draw(x, y int) {
 	}
 }
 
+// The menu takes part in the Tab order. The header is drawn in bold while the menu has focus.
+func (m *Menu) HandleFocus(focused bool) {
+	m.focused = focused
+}
+
+// The size of the area the menu is drawn in, including the header.
+func (m *Menu) Size() (width, height int) {
+	height = m.Height
+	if len(m.Header) > 0 {
+		height += 3
+	}
+	return m.Width, height
+}
+
+// Handles mouse input.
+// A left click on an option highlights it and executes its command, and the wheel moves the highlight like the arrow keys.
+func (m *Menu) HandleMouse(x, y int, key termbox.Key, results chan UIEvent) bool {
+	switch key {
+	case termbox.MouseWheelUp:
+		return m.HandleKey(termbox.KeyArrowUp, 0, results)
+	case termbox.MouseWheelDown:
+		return m.HandleKey(termbox.KeyArrowDown, 0, results)
+	case termbox.MouseLeft:
+	default:
+		return false
+	}
+
+	if len(m.Header) > 0 {
+		y -= 3
+	}
+
+	cols, rows, cellHeight := m.grid()
+	cellWidth := (m.Width+2*cols)/cols - 2
+	if y < 0 || cellWidth <= 0 || cellHeight <= 0 {
+		return false
+	}
+
+	col := x / cellWidth
+	index := getIndexFromCoordinates(rows, col, y/cellHeight+m.menuTop)
+	if col >= cols || index >= len(m.Options) {
+		return false
+	}
+
+	m.activeIndex = index
+	go m.Options[index].ExecuteCommand(results)
+	return true
+}
+
 // Handles input termbox key or character.
 // The arrow keys will change the active or highlighted menu option.
 // A number key will select the option at the specified index.
@@ -202,6 +255,29 @@ func (m *Menu) HandleKey(key termbox.Key, ch rune, results chan UIEvent) (eventC
 //        Utilities         //
 //==========================//
 
+// The number of columns and rows used to lay out the visible options, and the height of each row.
+func (m *Menu) grid() (cols, rows, cellHeight int) {
+	cols = 1
+	rows = m.menuBottom
+
+	if m.Mode == MenuGrid {
+		cols = 2
+		rows = m.menuBottom/cols + 1
+	}
+
+	// The table that draws the options never has more rows than its height.
+	if rows > 0 {
+		tableRows := rows
+		if m.menuBottom < tableRows {
+			tableRows = m.menuBottom
+		}
+		if tableRows > 0 {
+			cellHeight = m.menuBottom / tableRows
+		}
+	}
+	return
+}
+
 // Convert from the termbox window coordinates to the index of a menu option at that coordinate.
 func getIndexFromCoordinates(rows, col, row int) int {
 	return rows*col + row
@@ -0,0 +1,75 @@
+package termboxUI
+
+import (
+	"github.com/nsf/termbox-go"
+)
+
+//==========================//
+//          Mouse           //
+//==========================//
+
+// Sizer is implemented by fields that know the size of the area they draw.
+// A UI records the size of each field when it is drawn so that mouse events can be matched to the field under the pointer.
+type Sizer interface {
+	Size() (width, height int)
+}
+
+// MouseHandler is implemented by fields that react to the mouse.
+// A UI sends mouse events to the field under the pointer through HandleMouse. The x and y coordinates are relative to the
+// upper-left corner of the field and key is one of the termbox mouse keys. It returns 'true' if the event was used.
+// Fields also need to implement Sizer to receive mouse events.
+type MouseHandler interface {
+	HandleMouse(x, y int, key termbox.Key, event chan UIEvent) bool
+}
+
+// EnableMouse turns mouse reporting on or off for the UI.
+// It can be called before the UI starts, and is applied to the screen as soon as it is running.
+func (ui *UI) EnableMouse(enabled bool) {
+	ui.MouseEnabled = enabled
+	if ui.results != nil {
+		ui.applyInputMode()
+	}
+}
+
+// Set the screen input mode to match the MouseEnabled setting.
+// Escapes are always reported as the Esc key. TermboxScreen tells Alt+key apart from Esc by how quickly the key follows.
+func (ui *UI) applyInputMode() {
+	mode := termbox.InputEsc
+	if ui.MouseEnabled {
+		mode |= termbox.InputMouse
+	}
+	ui.screen().SetInputMode(mode)
+}
+
+// HandleMouse sends a mouse event at the given screen coordinates to the topmost field under the pointer.
+// A left click also gives that field the focus when it can take it.
+// Button releases are ignored.
+func (ui *UI) HandleMouse(x, y int, key termbox.Key, event chan UIEvent) (eventConsumed bool) {
+	return ui.handleMouse(x, y, key, 0, event)
+}
+
+// HandleMouse with the modifiers of the termbox event. Motion is ignored, as termbox reports a drag
+// as the button being pressed again with termbox.ModMotion, and fields only expect clicks.
+func (ui *UI) handleMouse(x, y int, key termbox.Key, mod termbox.Modifier, event chan UIEvent) (eventConsumed bool) {
+	if key == termbox.MouseRelease || mod&termbox.ModMotion != 0 {
+		return false
+	}
+
+	for i := len(ui.fields) - 1; i >= 0; i-- {
+		field := &ui.fields[i]
+		if x < field.X || y < field.Y || x >= field.X+field.Width || y >= field.Y+field.Height {
+			continue
+		}
+
+		if _, ok := field.Element.(FocusHandler); ok && key == termbox.MouseLeft && field.TabIndex >= 0 {
+			ui.focusIndex(i)
+			eventConsumed = true
+		}
+
+		if handler, ok := field.Element.(MouseHandler); ok {
+			eventConsumed = handler.HandleMouse(x-field.X, y-field.Y, key, event) || eventConsumed
+		}
+		return
+	}
+	return false
+}
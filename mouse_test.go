@@ -0,0 +1,96 @@
+package termboxUI
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestMouseClicksReachTheFieldUnderThePointer(t *testing.T) {
+	SetScreen(NewSimulationScreen(40, 10))
+	defer SetScreen(nil)
+
+	ui := new(UI)
+	button := CreateButton(6, 3, "ok", termbox.ColorDefault, termbox.ColorDefault)
+	button.Event = NewStringEvent(4, "clicked")
+	eb := CreateEditBox(20, "hello", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(button, 0, 0, false)
+	ui.AddField(eb, 10, 0, true)
+	ui.Draw()
+
+	ev := make(chan UIEvent, 1)
+	if !ui.HandleMouse(2, 1, termbox.MouseLeft, ev) {
+		t.Fatal("the click on the button was not used")
+	}
+	if event := <-ev; event.CustomType != 4 {
+		t.Errorf("the button sent custom type %d", event.CustomType)
+	}
+	if field := ui.FocusedField(); field == nil || field.Element != button {
+		t.Error("clicking the button did not give it the focus")
+	}
+
+	// The text starts after the "/> " prompt.
+	ui.HandleMouse(10+3+2, 2, termbox.MouseLeft, ev)
+	if eb.CursorIndex != 2 || !eb.focused {
+		t.Errorf("clicking the edit box put the cursor at %d", eb.CursorIndex)
+	}
+
+	if ui.HandleMouse(35, 8, termbox.MouseLeft, ev) {
+		t.Error("a click outside every field was used")
+	}
+	if ui.HandleMouse(2, 1, termbox.MouseRelease, ev) {
+		t.Error("a button release was used")
+	}
+}
+
+func TestEnableMouseSetsTheInputMode(t *testing.T) {
+	screen := NewSimulationScreen(40, 10)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	ui := new(UI)
+	ui.results = make(chan UIEvent, 1)
+	ui.EnableMouse(true)
+	if screen.InputMode()&termbox.InputMouse == 0 {
+		t.Error("mouse reporting is off")
+	}
+	ui.EnableMouse(false)
+	if screen.InputMode()&termbox.InputMouse != 0 {
+		t.Error("mouse reporting is still on")
+	}
+}
+
+func TestMouseMotionIsIgnored(t *testing.T) {
+	ui := new(UI)
+	ui.EnableMouse(true)
+	eb := CreateEditBox(20, "hello world", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(eb, 0, 0, true)
+
+	// The text starts after the "/> " prompt. Dragging along it must not move the cursor again.
+	drag := MouseEvent(3+8, 2, termbox.MouseLeft)
+	drag.Mod = termbox.ModMotion
+	runUntilQuit(t, ui, NewSimulationScreen(40, 10),
+		MouseEvent(3+2, 2, termbox.MouseLeft),
+		drag,
+		KeyEvent(termbox.KeyEsc, 0),
+	)
+	if eb.CursorIndex != 2 {
+		t.Errorf("dragging moved the cursor to %d", eb.CursorIndex)
+	}
+}
+
+func TestButtonDoesNotWaitToSendItsEvent(t *testing.T) {
+	button := CreateButton(6, 3, "ok", termbox.ColorDefault, termbox.ColorDefault)
+	ev := make(chan UIEvent)
+
+	button.HandleKey(termbox.KeyEnter, 0, ev)
+	button.HandleMouse(1, 1, termbox.MouseLeft, ev)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ev:
+		case <-time.After(time.Second):
+			t.Fatal("the button's event was not sent")
+		}
+	}
+}
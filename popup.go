@@ -21,6 +21,7 @@ const (
 
 // A popup is a simple text box that can be justified to the top, bottom or centered
 // It can be just a title or a title with a line break and limited text
+// With Markup set, the title and the content can be styled with markup tags, see ParseMarkup.
 type Popup struct {
 	Title    string
 	Content  string
@@ -32,6 +33,7 @@ type Popup struct {
 	Height   int
 	Fg       termbox.Attribute
 	Bg       termbox.Attribute
+	Markup   bool
 }
 
 func CreatePopup(title, content string, position /*, pType*/ uint16, height, width int, fg, bg termbox.Attribute) *Popup {
@@ -43,7 +45,7 @@ func CreatePopup(title, content string, position /*, pType*/ uint16, height, wid
 		popup.Position = PopupDefault
 	}
 
-	screenWidth, screenHeight := termbox.Size()
+	screenWidth, screenHeight := activeScreen.Size()
 
 	popup.Width = width
 	if width == -1 {
@@ -69,7 +71,7 @@ func CreatePopup(title, content string, position /*, pType*/ uint16, height, wid
 func (pu *Popup) Draw(x, y int) {
 	textBox := CreateTextBox(pu.Width, pu.Height, true, true, TextAlignmentCenter, TextAlignmentDefault, pu.Fg, pu.Bg)
 
-	screenWidth, screenHeight := termbox.Size()
+	screenWidth, screenHeight := activeScreen.Size()
 	x = (screenWidth - pu.Width) / 2
 	y = (screenHeight - pu.Height) / 2
 
@@ -79,7 +81,7 @@ func (pu *Popup) Draw(x, y int) {
 		y = screenHeight - pu.Height + 1
 	}
 
-	textBox.AddText(pu.Title)
+	textBox.addText(pu.Title, pu.Markup)
 
 	if len(pu.Content) > 0 {
 		bar := ""
@@ -88,7 +90,7 @@ func (pu *Popup) Draw(x, y int) {
 		}
 
 		textBox.AddText(bar)
-		textBox.AddText(pu.Content)
+		textBox.addText(pu.Content, pu.Markup)
 	}
 
 	textBox.Draw(x, y)
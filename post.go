@@ -0,0 +1,114 @@
+package termboxUI
+
+import (
+	"sync"
+)
+
+//==========================//
+//         Mailbox          //
+//==========================//
+
+// A mailbox holds the work sent to a UI from other goroutines until the event loop gets to it.
+// When StartUI rebuilds the UI, the new UI takes over the mailbox so that nothing sent to an older UI is lost.
+type mailbox struct {
+	mutex sync.Mutex
+	items []mailboxItem
+	wake  chan struct{}
+}
+
+// Either a function to run or an event to dispatch.
+type mailboxItem struct {
+	action func()
+	event  *UIEvent
+}
+
+func newMailbox() *mailbox {
+	return &mailbox{wake: make(chan struct{}, 1)}
+}
+
+func (mb *mailbox) push(item mailboxItem) {
+	mb.mutex.Lock()
+	mb.items = append(mb.items, item)
+	mb.mutex.Unlock()
+
+	select {
+	case mb.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Take everything that is waiting in the mailbox.
+func (mb *mailbox) drain() []mailboxItem {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	items := mb.items
+	mb.items = nil
+	return items
+}
+
+// Move the results that fields send to the UI into the mailbox until stop is called.
+// Fields send some results from the event loop itself, so the loop must never be the one to receive them.
+func (mb *mailbox) collect(results chan UIEvent) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case event := <-results:
+				mb.push(mailboxItem{event: &event})
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// Get the UI's mailbox, creating it the first time it is needed.
+func (ui *UI) getMailbox() *mailbox {
+	ui.mailboxMutex.Lock()
+	defer ui.mailboxMutex.Unlock()
+
+	if ui.mailbox == nil {
+		ui.mailbox = newMailbox()
+	}
+	return ui.mailbox
+}
+
+// Hand the mailbox of this UI over to the UI that replaces it.
+func (ui *UI) shareMailbox(newUI *UI) {
+	mb := ui.getMailbox()
+	if pending := newUI.getMailbox(); pending != mb {
+		for _, item := range pending.drain() {
+			mb.push(item)
+		}
+	}
+
+	newUI.mailboxMutex.Lock()
+	newUI.mailbox = mb
+	newUI.mailboxMutex.Unlock()
+}
+
+// Post runs a function on the goroutine of the UI event loop and then redraws the UI.
+// It is safe to call from any goroutine, and is the way for background work to change fields while the UI is running.
+// Functions posted before the UI starts are run once it does.
+func (ui *UI) Post(action func()) {
+	ui.getMailbox().push(mailboxItem{action: action})
+}
+
+// Send delivers an event to the UI's event handlers, exactly as if a field had reported it.
+// It is safe to call from any goroutine.
+func (ui *UI) Send(event UIEvent) {
+	ui.getMailbox().push(mailboxItem{event: &event})
+}
+
+// Attacher is implemented by fields that need to know the UI they are part of, for instance to Post work from a goroutine.
+// Attach is called when the field is added to a UI with AddField or ReplaceField.
+type Attacher interface {
+	Attach(ui *UI)
+}
+
+// RequestRedraw asks for the UI to be redrawn. Unlike Invalidate, it is safe to call from any goroutine.
+func (ui *UI) RequestRedraw() {
+	ui.Post(nil)
+}
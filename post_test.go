@@ -0,0 +1,80 @@
+package termboxUI
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestPostAndSendFromOtherGoroutines(t *testing.T) {
+	screen := NewSimulationScreen(40, 10)
+	SetScreen(screen)
+	t.Cleanup(func() { SetScreen(nil) })
+
+	ui := new(UI)
+	box := CreateTextBox(30, 4, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(box, 0, 0, false)
+	received := make(chan string, 1)
+	ui.CustomEvents = map[uint16]func(UIEvent){7: func(event UIEvent) {
+		text, _ := event.StringResult()
+		received <- text
+	}}
+
+	// Work posted before the UI starts runs once it does.
+	ui.Post(func() { box.AddText("posted early") })
+	runInBackground(t, ui.Run)
+	waitForText(t, screen, "posted early")
+
+	go ui.Post(func() { box.AddText("posted later") })
+	waitForText(t, screen, "posted later")
+
+	go ui.Send(NewStringEvent(7, "sent"))
+	select {
+	case text := <-received:
+		if text != "sent" {
+			t.Errorf("the handler got %q", text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the event sent to the UI was not handled")
+	}
+}
+
+func TestRunStopsWithItsContext(t *testing.T) {
+	SetScreen(NewSimulationScreen(40, 10))
+	defer SetScreen(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- new(UI).Run(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Run returned %v, want the context's error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop when its context was cancelled")
+	}
+}
+
+func TestPostQuitStopsTheUI(t *testing.T) {
+	SetScreen(NewSimulationScreen(40, 10))
+	defer SetScreen(nil)
+
+	ui := new(UI)
+	done := make(chan error)
+	go func() { done <- ui.Start() }()
+	ui.Post(ui.Quit)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start returned %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Post(ui.Quit) did not stop the UI")
+	}
+}
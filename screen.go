@@ -0,0 +1,311 @@
+package termboxUI
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+//==========================//
+//          Screen          //
+//==========================//
+
+// Screen is the drawing and input backend used by a UI and all of its fields.
+// Every cell, cursor and input event passes through the UI's Screen, so an alternative backend can be
+// used in place of the terminal by setting UI.Screen, or by passing it to SetScreen to make it the default.
+type Screen interface {
+	Init() error
+	Close()
+	SetCell(x, y int, ch rune, fg, bg termbox.Attribute)
+	Size() (width, height int)
+	SetCursor(x, y int)
+	HideCursor()
+	Clear(fg, bg termbox.Attribute) error
+	Flush() error
+	Sync() error
+	SetInputMode(mode termbox.InputMode) termbox.InputMode
+	PollEvent() termbox.Event
+	Interrupt()
+}
+
+// The screen that fields draw to. While a UI draws or handles input this is the UI's screen, otherwise it is the default.
+// It is swapped while other goroutines may be building fields, so it is read and written atomically.
+var activeScreen Screen = screenProxy{}
+
+var currentScreen atomic.Value // a screenHolder
+
+// atomic.Value needs every value stored to have the same type.
+type screenHolder struct{ Screen }
+
+func setActiveScreen(screen Screen) {
+	currentScreen.Store(screenHolder{screen})
+}
+
+// Forwards every call to the current screen.
+type screenProxy struct{}
+
+func (screenProxy) get() Screen { return currentScreen.Load().(screenHolder).Screen }
+
+func (p screenProxy) Init() error { return p.get().Init() }
+func (p screenProxy) Close()      { p.get().Close() }
+func (p screenProxy) SetCell(x, y int, ch rune, fg, bg termbox.Attribute) {
+	p.get().SetCell(x, y, ch, fg, bg)
+}
+func (p screenProxy) Size() (width, height int)            { return p.get().Size() }
+func (p screenProxy) SetCursor(x, y int)                   { p.get().SetCursor(x, y) }
+func (p screenProxy) HideCursor()                          { p.get().HideCursor() }
+func (p screenProxy) Clear(fg, bg termbox.Attribute) error { return p.get().Clear(fg, bg) }
+func (p screenProxy) Flush() error                         { return p.get().Flush() }
+func (p screenProxy) Sync() error                          { return p.get().Sync() }
+func (p screenProxy) SetInputMode(mode termbox.InputMode) termbox.InputMode {
+	return p.get().SetInputMode(mode)
+}
+func (p screenProxy) PollEvent() termbox.Event { return p.get().PollEvent() }
+func (p screenProxy) Interrupt()               { p.get().Interrupt() }
+
+// Fields draw through activeScreen, so a UI points it at its own screen while it uses it, and UIs running at the same time
+// take turns. The UIs of one run share a mailbox, which identifies the run holding the screen so that an event handler
+// drawing the UI does not wait on itself.
+var screens struct {
+	sync.Mutex
+	defaultScreen Screen
+	owner         *mailbox
+	ui            *UI // the UI that took the screen
+}
+
+var screenTurn sync.Mutex
+
+func init() {
+	screens.defaultScreen = new(TermboxScreen)
+	setActiveScreen(screens.defaultScreen)
+}
+
+// SetScreen replaces the default backend, used by UIs without a Screen of their own and by fields drawn outside of a UI.
+// Passing nil restores the default termbox backend.
+func SetScreen(screen Screen) {
+	if screen == nil {
+		screen = new(TermboxScreen)
+	}
+	screens.Lock()
+	defer screens.Unlock()
+	screens.defaultScreen = screen
+	if screens.owner == nil {
+		setActiveScreen(screen)
+	}
+}
+
+// GetScreen returns the default backend.
+func GetScreen() Screen {
+	screens.Lock()
+	defer screens.Unlock()
+	return screens.defaultScreen
+}
+
+// The screen the UI runs on: its own Screen, or the default one.
+func (ui *UI) screen() Screen {
+	if ui.Screen != nil {
+		return ui.Screen
+	}
+	return GetScreen()
+}
+
+// The UI that fields are being drawn for, or nil when they are drawn outside of a UI.
+func screenUI() *UI {
+	screens.Lock()
+	defer screens.Unlock()
+	return screens.ui
+}
+
+// Run f with the fields drawing to the UI's screen.
+func (ui *UI) useScreen(f func()) {
+	run := ui.getMailbox()
+	screens.Lock()
+	nested := screens.owner == run
+	screens.Unlock()
+	if nested {
+		f()
+		return
+	}
+
+	screenTurn.Lock()
+	defer screenTurn.Unlock()
+	screen := ui.screen()
+	screens.Lock()
+	screens.owner, screens.ui = run, ui
+	setActiveScreen(screen)
+	screens.Unlock()
+	defer func() {
+		screens.Lock()
+		screens.owner, screens.ui = nil, nil
+		setActiveScreen(screens.defaultScreen)
+		screens.Unlock()
+	}()
+	f()
+}
+
+//==========================//
+//      Termbox Screen      //
+//==========================//
+
+// TermboxScreen is the default Screen. It forwards every call to the termbox-go package.
+// Input is read raw so that the extended keys termbox does not know about, such as KeyBacktab, can be recognized.
+// Bracketed paste is turned on while the screen is open, so pasted text arrives between KeyPasteStart and KeyPasteEnd.
+// Terminals send Alt+key as an escape followed by the key, so an escape that is followed at once by another key
+// is reported as that key with termbox.ModAlt, and an escape that is followed by nothing is the Esc key.
+// Escape sequences that neither termbox nor the extended keys know are dropped whole rather than read as Alt+[.
+type TermboxScreen struct {
+	terminal      io.Writer // where the bracketed paste sequences are written
+	closeTerminal func()
+
+	buffer   []byte
+	pending  []byte
+	timeouts int32 // interrupts sent because the rest of the pending input did not arrive in time
+
+	pollRaw   func(data []byte) termbox.Event // termbox.PollRawEvent unless replaced by a test
+	interrupt func()                          // termbox.Interrupt unless replaced by a test
+}
+
+// How long to wait for the rest of an escape sequence, or for the key that follows an escape for Alt.
+// Terminals send the whole sequence at once, while a person can't type two keys this quickly.
+const escapeTimeout = 50 * time.Millisecond
+
+func (s *TermboxScreen) Init() error {
+	if err := termbox.Init(); err != nil {
+		return err
+	}
+	s.terminal, s.closeTerminal = openTerminal()
+	io.WriteString(s.terminal, "\x1b[?2004h")
+	return nil
+}
+
+func (s *TermboxScreen) Close() {
+	if s.terminal != nil {
+		io.WriteString(s.terminal, "\x1b[?2004l")
+		s.closeTerminal()
+		s.terminal = nil
+	}
+	termbox.Close()
+}
+
+// Open the terminal that termbox draws to, for the escape sequences termbox doesn't send itself.
+// Standard output can be redirected while termbox still draws to the terminal, so it is only used when there is no /dev/tty.
+func openTerminal() (terminal io.Writer, closeTerminal func()) {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return os.Stdout, func() {}
+	}
+	return tty, func() { tty.Close() }
+}
+
+func (s *TermboxScreen) SetCell(x, y int, ch rune, fg, bg termbox.Attribute) {
+	termbox.SetCell(x, y, ch, fg, bg)
+}
+
+func (s *TermboxScreen) Size() (width, height int) { return termbox.Size() }
+
+func (s *TermboxScreen) SetCursor(x, y int) { termbox.SetCursor(x, y) }
+
+func (s *TermboxScreen) HideCursor() { termbox.HideCursor() }
+
+func (s *TermboxScreen) Clear(fg, bg termbox.Attribute) error { return termbox.Clear(fg, bg) }
+
+func (s *TermboxScreen) Flush() error { return termbox.Flush() }
+
+func (s *TermboxScreen) Sync() error { return termbox.Sync() }
+
+func (s *TermboxScreen) SetInputMode(mode termbox.InputMode) termbox.InputMode {
+	return termbox.SetInputMode(mode)
+}
+
+// PollEvent waits for the next event from the terminal.
+// Raw input is split into events here, checking for the extended key sequences before handing the bytes to termbox.
+func (s *TermboxScreen) PollEvent() termbox.Event {
+	if s.buffer == nil {
+		s.buffer = make([]byte, 64)
+	}
+
+	if s.pollRaw == nil {
+		s.pollRaw = termbox.PollRawEvent
+	}
+
+	timedOut := false
+	for {
+		// An escape sequence or a character may be split across reads, such as at the end of a long paste,
+		// so wait a moment for the rest of it. After that, the input is taken as it is.
+		if len(s.pending) > 0 && (timedOut || !incompleteInput(s.pending)) {
+			if ev, ok := s.parsePending(); ok {
+				return ev
+			}
+			continue
+		}
+
+		var timer *time.Timer
+		if len(s.pending) > 0 {
+			timer = time.AfterFunc(escapeTimeout, func() {
+				atomic.AddInt32(&s.timeouts, 1)
+				s.Interrupt()
+			})
+		}
+		ev := s.pollRaw(s.buffer)
+		if timer != nil {
+			timer.Stop()
+		}
+
+		switch {
+		case ev.Type == termbox.EventInterrupt && atomic.LoadInt32(&s.timeouts) > 0:
+			// Every interrupt arrives once, so one sent by Interrupt while the timer was running is still returned later.
+			atomic.AddInt32(&s.timeouts, -1)
+			timedOut = true
+		case ev.Type != termbox.EventRaw:
+			return ev
+		default:
+			s.pending = append(s.pending, s.buffer[:ev.N]...)
+			timedOut = false
+		}
+	}
+}
+
+// Interrupt makes a waiting PollEvent return an event of type termbox.EventInterrupt.
+func (s *TermboxScreen) Interrupt() {
+	if s.interrupt != nil {
+		s.interrupt()
+		return
+	}
+	termbox.Interrupt()
+}
+
+// Take a single event from the front of the pending raw input.
+// Input that cannot be parsed is dropped a byte at a time, unknown escape sequences are dropped whole, and 'false' is returned.
+func (s *TermboxScreen) parsePending() (termbox.Event, bool) {
+	if key, n := parseExtendedKey(s.pending); n > 0 {
+		s.pending = s.pending[n:]
+		return termbox.Event{Type: termbox.EventKey, Key: key}, true
+	}
+
+	ev := termbox.ParseEvent(s.pending)
+	if ev.N <= 0 || ev.N > len(s.pending) {
+		s.pending = s.pending[1:]
+		return ev, false
+	}
+
+	// A whole sequence that neither table knows, such as Ctrl+Delete or F13, is dropped as one.
+	if n := escapeSequenceLength(s.pending); ev.Type == termbox.EventKey && ev.Key == termbox.KeyEsc && ev.N == 1 && n > 0 {
+		s.pending = s.pending[n:]
+		return ev, false
+	}
+
+	// An escape that is not part of a sequence is Alt with the key after it, unless that key is another escape.
+	if ev.Type == termbox.EventKey && ev.Key == termbox.KeyEsc && ev.N == 1 && len(s.pending) > 1 && s.pending[1] != '\x1b' {
+		if alt := termbox.ParseEvent(s.pending[1:]); alt.Type == termbox.EventKey && alt.N > 0 {
+			alt.Mod |= termbox.ModAlt
+			alt.N++
+			ev = alt
+		}
+	}
+	s.pending = s.pending[ev.N:]
+	return ev, ev.Type != termbox.EventNone
+}
@@ -0,0 +1,124 @@
+package termboxUI
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+// A terminal screen that reads the given chunks of raw input, one per read. More can be sent on the returned channel,
+// where an empty chunk stands for an interrupt.
+func rawInputScreen(t *testing.T, chunks ...string) (*TermboxScreen, chan<- string) {
+	input := make(chan string, 16)
+	for _, chunk := range chunks {
+		input <- chunk
+	}
+
+	screen := &TermboxScreen{interrupt: func() { input <- "" }}
+	screen.pollRaw = func(data []byte) termbox.Event {
+		select {
+		case chunk := <-input:
+			if chunk == "" {
+				return termbox.Event{Type: termbox.EventInterrupt}
+			}
+			return termbox.Event{Type: termbox.EventRaw, N: copy(data, chunk)}
+		case <-time.After(time.Second):
+			t.Fatal("PollEvent waited for input that never came")
+			return termbox.Event{}
+		}
+	}
+	return screen, input
+}
+
+func expectKeys(t *testing.T, screen *TermboxScreen, expected ...termbox.Event) {
+	t.Helper()
+	for i, want := range expected {
+		got := screen.PollEvent()
+		if got.Type != termbox.EventKey || got.Key != want.Key || got.Ch != want.Ch || got.Mod != want.Mod {
+			t.Fatalf("event %d: got key %#x ch %q mod %d, want key %#x ch %q mod %d", i, got.Key, got.Ch, got.Mod, want.Key, want.Ch, want.Mod)
+		}
+	}
+}
+
+func TestPollEventSplitsInput(t *testing.T) {
+	screen, _ := rawInputScreen(t, "ab\x1b[200~c\x1b[201~")
+	expectKeys(t, screen, KeyEvent(0, 'a'), KeyEvent(0, 'b'), KeyEvent(KeyPasteStart, 0), KeyEvent(0, 'c'), KeyEvent(KeyPasteEnd, 0))
+}
+
+func TestPollEventWaitsForSplitSequences(t *testing.T) {
+	screen, _ := rawInputScreen(t, "\x1b[1;2", "D", "\x1b[20", "1~")
+	expectKeys(t, screen, KeyEvent(KeyShiftArrowLeft, 0), KeyEvent(KeyPasteEnd, 0))
+}
+
+func TestPollEventWaitsForSplitCharacters(t *testing.T) {
+	screen, _ := rawInputScreen(t, "x\xc3", "\xa9y")
+	expectKeys(t, screen, KeyEvent(0, 'x'), KeyEvent(0, 'é'), KeyEvent(0, 'y'))
+}
+
+func TestIncompleteInput(t *testing.T) {
+	tests := map[string]bool{
+		"a":             false,
+		"\xe2\x82":      true,
+		"\xe2\x82\xac":  false,
+		"\x1b":          true,
+		"\x1b[":         true,
+		"\x1b[1;2":      true,
+		"\x1b[1;2D":     false,
+		"\x1b[M ":       true,
+		"\x1b[M !!":     false,
+		"\x1b[<0;10;5":  true,
+		"\x1b[<0;10;5M": false,
+		"\x1bO":         true,
+		"\x1bOP":        false,
+		"\x1bb":         false,
+		"\x1b\xc3":      true,
+	}
+	for input, want := range tests {
+		if got := incompleteInput([]byte(input)); got != want {
+			t.Errorf("incompleteInput(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestPollEventReportsLoneEscape(t *testing.T) {
+	screen, _ := rawInputScreen(t, "\x1b")
+	expectKeys(t, screen, KeyEvent(termbox.KeyEsc, 0))
+
+	screen, _ = rawInputScreen(t, "\x1b\x1b")
+	expectKeys(t, screen, KeyEvent(termbox.KeyEsc, 0), KeyEvent(termbox.KeyEsc, 0))
+}
+
+func TestPollEventReportsAlt(t *testing.T) {
+	screen, _ := rawInputScreen(t, "\x1bb", "\x1b", "\x7f", "\x1b[")
+	expectKeys(t, screen,
+		termbox.Event{Ch: 'b', Mod: termbox.ModAlt},
+		termbox.Event{Key: termbox.KeyBackspace2, Mod: termbox.ModAlt},
+		termbox.Event{Ch: '[', Mod: termbox.ModAlt},
+	)
+}
+
+func TestPollEventDropsUnknownSequences(t *testing.T) {
+	// Ctrl+Left, Ctrl+Delete and F13 are not known, and none of their bytes may come through as keys.
+	screen, _ := rawInputScreen(t, "\x1b[1;5Dx\x1b[3;5~", "\x1b[25~y")
+	expectKeys(t, screen, KeyEvent(0, 'x'), KeyEvent(0, 'y'))
+}
+
+func TestPollEventDropsUnfinishedCharacters(t *testing.T) {
+	screen, input := rawInputScreen(t, "\xc3")
+	go func() {
+		time.Sleep(2 * escapeTimeout)
+		input <- "x"
+	}()
+	expectKeys(t, screen, KeyEvent(0, 'x'))
+}
+
+func TestPollEventPassesInterruptsOn(t *testing.T) {
+	screen, _ := rawInputScreen(t, "\x1b")
+	expectKeys(t, screen, KeyEvent(termbox.KeyEsc, 0))
+
+	screen.Interrupt()
+	if ev := screen.PollEvent(); ev.Type != termbox.EventInterrupt {
+		t.Fatalf("got event type %d, want an interrupt", ev.Type)
+	}
+}
@@ -0,0 +1,269 @@
+package termboxUI
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+//==========================//
+//    Simulation Screen     //
+//==========================//
+
+// SimulationScreen is a headless Screen that draws into an in-memory cell buffer.
+// It is meant for tests: set it as a UI's Screen or pass it to SetScreen, draw a UI or a single field, and then inspect the result
+// with Cell or String. Input is delivered to PollEvent by InjectEvent and the other Inject helpers.
+type SimulationScreen struct {
+	mutex   sync.Mutex
+	width   int
+	height  int
+	cells   []termbox.Cell
+	cursorX int
+	cursorY int
+	mode    termbox.InputMode
+	events  chan termbox.Event
+}
+
+// NewSimulationScreen creates a headless screen with the given dimensions.
+// Every cell starts out as a space with the default attributes and the cursor is hidden.
+func NewSimulationScreen(width, height int) *SimulationScreen {
+	screen := new(SimulationScreen)
+	screen.events = make(chan termbox.Event, 256)
+	screen.mode = termbox.InputEsc
+	screen.resize(width, height)
+	return screen
+}
+
+func (s *SimulationScreen) resize(width, height int) {
+	s.width = width
+	s.height = height
+	s.cells = make([]termbox.Cell, width*height)
+	for i := range s.cells {
+		s.cells[i] = termbox.Cell{Ch: ' '}
+	}
+	s.cursorX, s.cursorY = -1, -1
+}
+
+func (s *SimulationScreen) Init() error { return nil }
+
+func (s *SimulationScreen) Close() {}
+
+// SetCell changes a single cell of the buffer. Cells outside of the screen are ignored, like termbox does.
+func (s *SimulationScreen) SetCell(x, y int, ch rune, fg, bg termbox.Attribute) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if x < 0 || y < 0 || x >= s.width || y >= s.height {
+		return
+	}
+	s.cells[y*s.width+x] = termbox.Cell{Ch: ch, Fg: fg, Bg: bg}
+
+	// A wide character also covers the next cell, which is left empty like termbox does.
+	if runewidth.RuneWidth(ch) == 2 && x+1 < s.width {
+		s.cells[y*s.width+x+1] = termbox.Cell{Fg: fg, Bg: bg}
+	}
+}
+
+func (s *SimulationScreen) Size() (width, height int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.width, s.height
+}
+
+func (s *SimulationScreen) SetCursor(x, y int) {
+	s.mutex.Lock()
+	s.cursorX, s.cursorY = x, y
+	s.mutex.Unlock()
+}
+
+func (s *SimulationScreen) HideCursor() { s.SetCursor(-1, -1) }
+
+// Clear resets every cell to a space with the given attributes.
+func (s *SimulationScreen) Clear(fg, bg termbox.Attribute) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.cells {
+		s.cells[i] = termbox.Cell{Ch: ' ', Fg: fg, Bg: bg}
+	}
+	return nil
+}
+
+// Flush does nothing since the buffer is always up to date.
+func (s *SimulationScreen) Flush() error { return nil }
+
+// Sync does nothing for the same reason.
+func (s *SimulationScreen) Sync() error { return nil }
+
+// SetInputMode records the input mode like termbox does. Use InputMode to check it.
+// The mode has no effect on injected events.
+func (s *SimulationScreen) SetInputMode(mode termbox.InputMode) termbox.InputMode {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if mode != termbox.InputCurrent {
+		s.mode = mode
+	}
+	return s.mode
+}
+
+// PollEvent blocks until an event is injected into the screen.
+func (s *SimulationScreen) PollEvent() termbox.Event { return <-s.events }
+
+// Interrupt queues an event of type termbox.EventInterrupt.
+func (s *SimulationScreen) Interrupt() {
+	s.InjectEvent(termbox.Event{Type: termbox.EventInterrupt})
+}
+
+//==========================//
+//   Simulation Inspection  //
+//==========================//
+
+// Cell returns the cell at the given screen coordinates.
+// A zero-valued cell is returned for coordinates outside of the screen.
+func (s *SimulationScreen) Cell(x, y int) termbox.Cell {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if x < 0 || y < 0 || x >= s.width || y >= s.height {
+		return termbox.Cell{}
+	}
+	return s.cells[y*s.width+x]
+}
+
+// Cursor returns the position of the cursor. It is -1, -1 when the cursor is hidden.
+func (s *SimulationScreen) Cursor() (x, y int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cursorX, s.cursorY
+}
+
+// InputMode returns the input mode most recently set on the screen.
+func (s *SimulationScreen) InputMode() termbox.InputMode {
+	return s.SetInputMode(termbox.InputCurrent)
+}
+
+// Line returns the runes of a single row of the screen as plain text.
+// The empty cell covered by a wide character is left out, so that the text lines up the way it does on a terminal.
+func (s *SimulationScreen) Line(y int) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if y < 0 || y >= s.height {
+		return ""
+	}
+	line := make([]rune, 0, s.width)
+	for x := 0; x < s.width; x++ {
+		ch := s.cells[y*s.width+x].Ch
+		if ch == 0 {
+			if x > 0 && runewidth.RuneWidth(s.cells[y*s.width+x-1].Ch) == 2 {
+				continue
+			}
+			ch = ' '
+		}
+		line = append(line, ch)
+	}
+	return string(line)
+}
+
+// String dumps the whole screen as plain text, one line per row. Attributes are not included.
+func (s *SimulationScreen) String() string {
+	_, height := s.Size()
+
+	var buffer bytes.Buffer
+	for y := 0; y < height; y++ {
+		buffer.WriteString(s.Line(y))
+		buffer.WriteByte('\n')
+	}
+	return buffer.String()
+}
+
+//==========================//
+//     Simulation Input     //
+//==========================//
+
+// InjectEvent queues an event to be returned by PollEvent.
+func (s *SimulationScreen) InjectEvent(ev termbox.Event) {
+	s.events <- ev
+}
+
+// InjectKey queues a single key press. Use a key of 0 with a non-zero ch for a character.
+func (s *SimulationScreen) InjectKey(key termbox.Key, ch rune) {
+	s.InjectEvent(KeyEvent(key, ch))
+}
+
+// InjectKeys queues one key press per character of text.
+func (s *SimulationScreen) InjectKeys(text string) {
+	for _, ev := range TextEvents(text) {
+		s.InjectEvent(ev)
+	}
+}
+
+// InjectMouse queues a mouse event at the given screen coordinates.
+// The key is one of the termbox mouse keys, such as termbox.MouseLeft or termbox.MouseWheelUp.
+func (s *SimulationScreen) InjectMouse(x, y int, key termbox.Key) {
+	s.InjectEvent(MouseEvent(x, y, key))
+}
+
+// InjectResize changes the dimensions of the screen and queues the resize event that termbox would send.
+// The buffer is cleared by the resize.
+func (s *SimulationScreen) InjectResize(width, height int) {
+	s.mutex.Lock()
+	s.resize(width, height)
+	s.mutex.Unlock()
+
+	s.InjectEvent(termbox.Event{Type: termbox.EventResize, Width: width, Height: height})
+}
+
+// KeyEvent builds the termbox event for a single key press.
+func KeyEvent(key termbox.Key, ch rune) termbox.Event {
+	return termbox.Event{Type: termbox.EventKey, Key: key, Ch: ch}
+}
+
+// MouseEvent builds the termbox event for a mouse action at the given screen coordinates.
+func MouseEvent(x, y int, key termbox.Key) termbox.Event {
+	return termbox.Event{Type: termbox.EventMouse, Key: key, MouseX: x, MouseY: y}
+}
+
+// TextEvents builds the termbox key events that typing the given text would produce.
+// Spaces, tabs and newlines are reported as their keys rather than as characters, like termbox does.
+func TextEvents(text string) []termbox.Event {
+	events := make([]termbox.Event, 0, len(text))
+	for _, ch := range text {
+		switch ch {
+		case ' ':
+			events = append(events, KeyEvent(termbox.KeySpace, 0))
+		case '\t':
+			events = append(events, KeyEvent(termbox.KeyTab, 0))
+		case '\n', '\r':
+			events = append(events, KeyEvent(termbox.KeyEnter, 0))
+		default:
+			events = append(events, KeyEvent(0, ch))
+		}
+	}
+	return events
+}
+
+// FeedInput sends a sequence of key and mouse events through HandleInput and HandleMouse, as if the user had typed them.
+// Any other events are skipped. The number of events that were consumed by a field is returned.
+// Some fields send their results synchronously, so the event channel should be buffered or drained by the caller.
+// Mouse events only reach fields that have been drawn, since the field bounds are recorded by Draw.
+func (ui *UI) FeedInput(event chan UIEvent, input ...termbox.Event) (consumed int) {
+	ui.useScreen(func() {
+		for _, ev := range input {
+			switch ev.Type {
+			case termbox.EventKey:
+				if ui.handleKey(KeyBinding{Key: ev.Key, Ch: ev.Ch, Mod: ev.Mod}, event) {
+					consumed++
+				}
+			case termbox.EventMouse:
+				if ui.handleMouse(ev.MouseX, ev.MouseY, ev.Key, ev.Mod, event) {
+					consumed++
+				}
+			}
+		}
+	})
+	return
+}
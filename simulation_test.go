@@ -0,0 +1,79 @@
+package termboxUI
+
+import (
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestSimulationScreenDrawsFields(t *testing.T) {
+	screen := NewSimulationScreen(12, 3)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	if GetScreen() != screen {
+		t.Fatal("GetScreen does not return the screen given to SetScreen")
+	}
+
+	DrawText(1, 1, "hi 世界", termbox.ColorRed, termbox.ColorDefault)
+	if got := screen.Line(1); got != " hi 世界    " {
+		t.Errorf("line is %q", got)
+	}
+	if cell := screen.Cell(1, 1); cell.Ch != 'h' || cell.Fg != termbox.ColorRed {
+		t.Errorf("cell is %+v", cell)
+	}
+	if cell := screen.Cell(20, 1); cell != (termbox.Cell{}) {
+		t.Errorf("a cell off the screen is %+v", cell)
+	}
+	if x, y := screen.Cursor(); x != -1 || y != -1 {
+		t.Errorf("the cursor starts at %d,%d instead of hidden", x, y)
+	}
+
+	screen.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	if got := screen.Line(1); got != "            " {
+		t.Errorf("Clear left %q", got)
+	}
+}
+
+func TestSimulationScreenInput(t *testing.T) {
+	screen := NewSimulationScreen(40, 10)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	screen.InjectKeys("a b")
+	screen.InjectResize(20, 5)
+	want := []termbox.Event{KeyEvent(0, 'a'), KeyEvent(termbox.KeySpace, 0), KeyEvent(0, 'b')}
+	for _, expected := range want {
+		if ev := screen.PollEvent(); ev != expected {
+			t.Errorf("got %+v, want %+v", ev, expected)
+		}
+	}
+	if ev := screen.PollEvent(); ev.Type != termbox.EventResize || ev.Width != 20 || ev.Height != 5 {
+		t.Errorf("got %+v, want a resize", ev)
+	}
+	if width, height := screen.Size(); width != 20 || height != 5 {
+		t.Errorf("the screen is %dx%d after the resize", width, height)
+	}
+
+	screen.Interrupt()
+	if ev := screen.PollEvent(); ev.Type != termbox.EventInterrupt {
+		t.Errorf("got %+v, want an interrupt", ev)
+	}
+}
+
+func TestFeedInput(t *testing.T) {
+	SetScreen(NewSimulationScreen(40, 10))
+	defer SetScreen(nil)
+
+	ui := new(UI)
+	eb := CreateEditBox(20, "", 0, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(eb, 0, 0, true)
+
+	ev := make(chan UIEvent, 1)
+	if consumed := ui.FeedInput(ev, TextEvents("ok\n")...); consumed != 3 {
+		t.Errorf("%d events were consumed", consumed)
+	}
+	if text, _ := (<-ev).StringResult(); text != "ok" {
+		t.Errorf("submitted %q", text)
+	}
+}
@@ -0,0 +1,157 @@
+package termboxUI
+
+import (
+	"strings"
+
+	"github.com/nsf/termbox-go"
+)
+
+//======================================================//
+// Styled Text
+//======================================================//
+
+// A span is a run of text drawn with a single style.
+// A span without a foreground or background color of its own is drawn with the colors of the field it is shown in.
+type Span struct {
+	Text      string
+	Fg        termbox.Attribute
+	Bg        termbox.Attribute
+	Bold      bool
+	Underline bool
+	Reverse   bool
+}
+
+// The termbox attributes to draw the span with, given the default colors of the field.
+func (s Span) attributes(fg, bg termbox.Attribute) (termbox.Attribute, termbox.Attribute) {
+	if s.Fg != termbox.ColorDefault {
+		fg = s.Fg
+	}
+	if s.Bg != termbox.ColorDefault {
+		bg = s.Bg
+	}
+	if s.Bold {
+		fg |= termbox.AttrBold
+	}
+	if s.Underline {
+		fg |= termbox.AttrUnderline
+	}
+	if s.Reverse {
+		fg |= termbox.AttrReverse
+	}
+	return fg, bg
+}
+
+// Spans of the same style as this one, with different text.
+func (s Span) withText(text string) Span {
+	s.Text = text
+	return s
+}
+
+// DrawSpans writes a single line of styled text to the terminal, like DrawText does for plain text.
+// 'fg' and 'bg' are used for spans that have no colors of their own.
+func DrawSpans(x, y int, spans []Span, fg, bg termbox.Attribute) (int, int) {
+	for _, span := range spans {
+		spanFg, spanBg := span.attributes(fg, bg)
+		x, y = DrawText(x, y, span.Text, spanFg, spanBg)
+	}
+	return x, y
+}
+
+// Draw the spans within 'width' columns starting at x, with the text shifted left by 'offset' columns.
+// A character that would only partly fit within the area is left out.
+func drawSpansClipped(x, y, width, offset int, spans []Span, fg, bg termbox.Attribute) {
+	column := -offset
+	for _, span := range spans {
+		spanFg, spanBg := span.attributes(fg, bg)
+		for text := span.Text; len(text) > 0; {
+			ch, chWidth, size := nextGrapheme(text)
+			text = text[size:]
+			if chWidth > 0 && column >= 0 && column+chWidth <= width {
+				activeScreen.SetCell(x+column, y, ch, spanFg, spanBg)
+			}
+			column += chWidth
+			if column >= width {
+				return
+			}
+		}
+	}
+}
+
+// The spans cut down to at most the given number of columns, never splitting a cluster.
+func truncateSpans(spans []Span, width int) []Span {
+	return sliceSpans(spans, 0, len(TruncateString(spansText(spans), width)))
+}
+
+// The text of the spans without their styles.
+func spansText(spans []Span) string {
+	var text strings.Builder
+	for _, span := range spans {
+		text.WriteString(span.Text)
+	}
+	return text.String()
+}
+
+// The number of terminal columns needed to display the spans.
+func spansWidth(spans []Span) (width int) {
+	for _, span := range spans {
+		width += StringWidth(span.Text)
+	}
+	return
+}
+
+// The part of the spans between two byte offsets of their combined text.
+func sliceSpans(spans []Span, start, end int) []Span {
+	var slice []Span
+	offset := 0
+	for _, span := range spans {
+		spanStart, spanEnd := offset, offset+len(span.Text)
+		offset = spanEnd
+		if spanEnd <= start || spanStart >= end {
+			continue
+		}
+		from, to := 0, len(span.Text)
+		if start > spanStart {
+			from = start - spanStart
+		}
+		if end < spanEnd {
+			to = end - spanStart
+		}
+		slice = append(slice, span.withText(span.Text[from:to]))
+	}
+	return slice
+}
+
+// Split the spans into lines at each '\n'.
+func splitSpanLines(spans []Span) [][]Span {
+	lines := [][]Span{nil}
+	for _, span := range spans {
+		parts := strings.Split(span.Text, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if len(part) > 0 {
+				lines[len(lines)-1] = append(lines[len(lines)-1], span.withText(part))
+			}
+		}
+	}
+	return lines
+}
+
+// Wrap a line of spans the same way WrapString wraps plain text. Styles carry over across the breaks,
+// and an added hyphen takes the style of the text before it.
+func wrapSpans(spans []Span, width int) [][]Span {
+	text := spansText(spans)
+	var lines [][]Span
+	for _, wrapped := range wrapText(text, width) {
+		var line []Span
+		for _, r := range wrapped.ranges {
+			line = append(line, sliceSpans(spans, r[0], r[1])...)
+		}
+		if wrapped.hyphen && len(line) > 0 {
+			line = append(line, line[len(line)-1].withText("-"))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,54 @@
+package termboxUI
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestDrawSpans(t *testing.T) {
+	screen := NewSimulationScreen(10, 1)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	DrawSpans(0, 0, []Span{{Text: "a"}, {Text: "b", Fg: termbox.ColorRed, Bold: true}, {Text: "c", Reverse: true}},
+		termbox.ColorBlue, termbox.ColorDefault)
+
+	want := []termbox.Attribute{termbox.ColorBlue, termbox.ColorRed | termbox.AttrBold, termbox.ColorBlue | termbox.AttrReverse}
+	for x, fg := range want {
+		if cell := screen.Cell(x, 0); cell.Fg != fg {
+			t.Errorf("cell %d has fg %d, want %d", x, cell.Fg, fg)
+		}
+	}
+}
+
+func TestWrapSpansKeepsStyles(t *testing.T) {
+	bold := Span{Text: "quick brown", Bold: true}
+	lines := wrapSpans([]Span{{Text: "the "}, bold, {Text: " fox"}}, 10)
+
+	want := [][]Span{
+		{{Text: "the "}, {Text: "quick", Bold: true}},
+		{{Text: "brown", Bold: true}, {Text: " fox"}},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("wrapped into %+v, want %+v", lines, want)
+	}
+}
+
+func TestTextBoxDrawsStyledText(t *testing.T) {
+	screen := NewSimulationScreen(20, 2)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	box := scrollingTextBox(20, 2, false)
+	box.AddStyledText(Span{Text: "ok "}, Span{Text: "fail", Fg: termbox.ColorRed, Underline: true})
+	box.Draw(0, 0)
+
+	if got := trimmedLine(screen, 0); got != "ok fail" {
+		t.Errorf("line is %q", got)
+	}
+	if cell := screen.Cell(3, 0); cell.Fg != termbox.ColorRed|termbox.AttrUnderline {
+		t.Errorf("the styled span has fg %d", cell.Fg)
+	}
+}
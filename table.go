@@ -22,6 +22,8 @@ type tableRow []tableCell
 
 // This is a spreadsheet/table for the termbox-go library.
 // If ActiveRow and ActiveColumn are both set, the table coordinate they represent will be the only one highlighted. If that location does not lay within the table definitions, only the valid row or column if either with be highlighted.
+// CellCommand is optional. When set, it is executed with the coordinates of a cell that is clicked, like a menu option command.
+// With Markup set, the text of the cells can be styled with markup tags, see ParseMarkup.
 type Table struct {
 	Height       int
 	Width        int
@@ -35,6 +37,8 @@ type Table struct {
 	ShowNumbers  bool
 	ActiveRow    int
 	ActiveColumn int
+	CellCommand  func(column, row int) UIEvent
+	Markup       bool
 
 	cells []tableRow
 }
@@ -140,7 +144,12 @@ func (t *Table) Draw(x, y int) {
 
 			if !skip {
 				cell := CreateTextBox(cellWidth, cellHeight, t.ShowGrid, false, h_justification, TextAlignmentCenter, fg, bg)
-				cell.AddText(text)
+				// Text that is too wide for the cell is cut off at the end, by display width, rather than centered past both edges.
+				line := []Span{{Text: text}}
+				if t.Markup {
+					line = ParseMarkup(text)
+				}
+				cell.AddStyledText(truncateSpans(line, cell.textWidth())...)
 				cell.Draw(x_coord, y_coord)
 			}
 		}
@@ -172,3 +181,36 @@ func cellIsActive(active_col, active_row, current_col, current_row int) bool {
 
 // Currently the table does not take any input directly.
 func (t *Table) HandleKey(key termbox.Key, ch rune, event chan UIEvent) bool { return false }
+
+// The size of the area the table is drawn in.
+func (t *Table) Size() (width, height int) {
+	return t.Width, t.Height
+}
+
+// Handles mouse input.
+// A left click activates the cell under the pointer and executes the CellCommand, if there is one.
+func (t *Table) HandleMouse(x, y int, key termbox.Key, event chan UIEvent) bool {
+	if key != termbox.MouseLeft || t.Columns <= 0 || t.Rows <= 0 {
+		return false
+	}
+
+	// The cells overlap by two columns, see Draw.
+	cellWidth := (t.Width+2*len(t.cells))/t.Columns - 2
+	cellHeight := t.Height / t.Rows
+	if cellWidth <= 0 || cellHeight <= 0 {
+		return false
+	}
+
+	column, row := x/cellWidth, y/cellHeight
+	if column >= t.Columns || row >= t.Rows {
+		return false
+	}
+
+	t.ActiveColumn, t.ActiveRow = column, row
+	if t.CellCommand != nil {
+		go func() {
+			event <- t.CellCommand(column, row)
+		}()
+	}
+	return true
+}
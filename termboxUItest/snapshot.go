@@ -0,0 +1,190 @@
+// Package termboxUItest provides utilities for testing termboxUI screens without a terminal.
+// UIs and single fields are rendered to a termboxUI.SimulationScreen and compared against golden files.
+// Run `TERMBOXUI_UPDATE=1 go test` to regenerate the golden files after an intended change.
+// Rendering only touches the screen it creates, so tests that render can run with t.Parallel.
+package termboxUItest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/C2FO/termboxUI"
+	"github.com/nsf/termbox-go"
+)
+
+// The environment variable that rewrites the golden files instead of comparing against them.
+// An environment variable is used rather than a flag so that it can't clash with the flags of the package under test.
+const updateVariable = "TERMBOXUI_UPDATE"
+
+func updating() bool {
+	return os.Getenv(updateVariable) != ""
+}
+
+// The characters used in the attribute map, in the order that attribute pairs are encountered.
+// Screens with more attribute pairs than this go on with letters from beyond ASCII. See legendKey.
+const legendKeys = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// The key for the attribute pair that was found in the given order, so that every pair on a screen has a key of its own.
+func legendKey(index int) rune {
+	if index < len(legendKeys) {
+		return rune(legendKeys[index])
+	}
+	index -= len(legendKeys)
+	for key := rune(0x100); ; key++ {
+		if unicode.IsLetter(key) && termboxUI.StringWidth(string(key)) == 1 {
+			if index == 0 {
+				return key
+			}
+			index--
+		}
+	}
+}
+
+//==========================//
+//        Rendering         //
+//==========================//
+
+// Render draws the whole UI to a new simulation screen of the given size and returns that screen.
+// The UI's own Screen is restored afterwards, and the default screen is left alone.
+// The UI must not be running or being rendered elsewhere at the same time.
+func Render(ui *termboxUI.UI, width, height int) *termboxUI.SimulationScreen {
+	screen := termboxUI.NewSimulationScreen(width, height)
+	previous := ui.Screen
+	ui.Screen = screen
+	defer func() {
+		ui.Screen = previous
+	}()
+
+	ui.Draw()
+	return screen
+}
+
+// RenderField draws a single field at x, y on a new simulation screen of the given size and returns that screen.
+// The field is drawn by a UI of its own, so its overlay is drawn too.
+func RenderField(element termboxUI.DrawHandler, x, y, width, height int) *termboxUI.SimulationScreen {
+	screen := termboxUI.NewSimulationScreen(width, height)
+	ui := &termboxUI.UI{Screen: screen}
+	ui.AddField(element, x, y, false)
+
+	ui.Draw()
+	return screen
+}
+
+//==========================//
+//        Snapshots         //
+//==========================//
+
+// Snapshot converts the screen to the text stored in golden files.
+// The text of the screen is followed by a map of the same size where each cell holds a key to the legend,
+// with one key per cell even where a wide character covers two cells,
+// then by the legend itself that lists the foreground and background attributes for each key.
+func Snapshot(screen *termboxUI.SimulationScreen) string {
+	width, height := screen.Size()
+
+	var text, attributes, legend bytes.Buffer
+	keys := make(map[[2]termbox.Attribute]rune)
+
+	for y := 0; y < height; y++ {
+		text.WriteString(screen.Line(y))
+		for x := 0; x < width; x++ {
+			cell := screen.Cell(x, y)
+			pair := [2]termbox.Attribute{cell.Fg, cell.Bg}
+			key, ok := keys[pair]
+			if !ok {
+				key = legendKey(len(keys))
+				keys[pair] = key
+				fmt.Fprintf(&legend, "%c fg=%s bg=%s\n", key, AttributeName(cell.Fg), AttributeName(cell.Bg))
+			}
+			attributes.WriteRune(key)
+		}
+		text.WriteByte('\n')
+		attributes.WriteByte('\n')
+	}
+
+	cursorX, cursorY := screen.Cursor()
+	return fmt.Sprintf("%s--- attributes ---\n%s--- legend ---\n%scursor %d,%d\n", text.String(), attributes.String(), legend.String(), cursorX, cursorY)
+}
+
+// AttributeName describes a termbox attribute as a color name followed by any style flags, such as "red|bold".
+// Colors outside of the eight basic ones are written as their number.
+func AttributeName(attribute termbox.Attribute) string {
+	names := []string{"default", "black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+	color := attribute &^ (termbox.AttrBold | termbox.AttrUnderline | termbox.AttrReverse)
+	name := fmt.Sprintf("%d", color)
+	if int(color) < len(names) {
+		name = names[color]
+	}
+
+	if attribute&termbox.AttrBold != 0 {
+		name += "|bold"
+	}
+	if attribute&termbox.AttrUnderline != 0 {
+		name += "|underline"
+	}
+	if attribute&termbox.AttrReverse != 0 {
+		name += "|reverse"
+	}
+	return name
+}
+
+// AssertSnapshot renders the UI at the given terminal size and compares it to testdata/<name>.golden.
+// When TERMBOXUI_UPDATE is set, the golden file is written instead.
+func AssertSnapshot(t testing.TB, name string, ui *termboxUI.UI, width, height int) {
+	t.Helper()
+	assertGolden(t, name, Snapshot(Render(ui, width, height)))
+}
+
+// AssertFieldSnapshot renders a single field at x, y on a screen of the given size and compares it to testdata/<name>.golden.
+// When TERMBOXUI_UPDATE is set, the golden file is written instead.
+func AssertFieldSnapshot(t testing.TB, name string, element termboxUI.DrawHandler, x, y, width, height int) {
+	t.Helper()
+	assertGolden(t, name, Snapshot(RenderField(element, x, y, width, height)))
+}
+
+// Compare the snapshot with the golden file, or rewrite the golden file when TERMBOXUI_UPDATE is set.
+func assertGolden(t testing.TB, name, actual string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("termboxUItest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatalf("termboxUItest: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("termboxUItest: %v (run with %s=1 to create it)", err, updateVariable)
+	}
+
+	if string(expected) == actual {
+		return
+	}
+
+	expectedLines := strings.Split(string(expected), "\n")
+	actualLines := strings.Split(actual, "\n")
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		var want, got string
+		if i < len(expectedLines) {
+			want = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			got = actualLines[i]
+		}
+		if want != got {
+			t.Errorf("termboxUItest: snapshot %s differs at line %d\nwant: %q\n got: %q\n\nfull snapshot:\n%s", path, i+1, want, got, actual)
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package termboxUItest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/C2FO/termboxUI"
+	"github.com/nsf/termbox-go"
+)
+
+func TestSnapshotKeysEveryAttributePair(t *testing.T) {
+	screen := termboxUI.NewSimulationScreen(100, 1)
+	for x := 0; x < 100; x++ {
+		screen.SetCell(x, 0, 'x', termbox.Attribute(x+1), termbox.ColorDefault)
+	}
+
+	snapshot := Snapshot(screen)
+	legend := snapshot[strings.Index(snapshot, "--- legend ---\n"):]
+	keys := make(map[rune]bool)
+	for _, line := range strings.Split(legend, "\n")[1:101] {
+		key := []rune(line)[0]
+		if keys[key] {
+			t.Fatalf("key %q is used for more than one attribute pair:\n%s", key, legend)
+		}
+		keys[key] = true
+	}
+}
+
+func TestAttributeName(t *testing.T) {
+	tests := map[termbox.Attribute]string{
+		termbox.ColorDefault:                           "default",
+		termbox.ColorRed | termbox.AttrBold:            "red|bold",
+		termbox.ColorBlue | termbox.AttrReverse:        "blue|reverse",
+		termbox.Attribute(200) | termbox.AttrUnderline: "200|underline",
+	}
+	for attribute, want := range tests {
+		if got := AttributeName(attribute); got != want {
+			t.Errorf("AttributeName(%d) = %q, want %q", attribute, got, want)
+		}
+	}
+}
+
+// Records failures instead of failing the test, to check that a snapshot that differs is reported.
+type failureRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *failureRecorder) Errorf(format string, args ...interface{}) { r.failed = true }
+func (r *failureRecorder) Fatalf(format string, args ...interface{}) { r.failed = true }
+
+func TestAssertFieldSnapshot(t *testing.T) {
+	button := termboxUI.CreateButton(8, 3, "ok", termbox.ColorGreen, termbox.ColorDefault)
+	AssertFieldSnapshot(t, "button", button, 1, 0, 10, 3)
+
+	if updating() {
+		return
+	}
+	button.Text = "no"
+	recorder := &failureRecorder{TB: t}
+	AssertFieldSnapshot(recorder, "button", button, 1, 0, 10, 3)
+	if !recorder.failed {
+		t.Error("a snapshot that differs from its golden file passed")
+	}
+}
+
+func TestRenderInParallel(t *testing.T) {
+	for _, text := range []string{"a", "b", "c", "d"} {
+		text := text
+		t.Run(text, func(t *testing.T) {
+			button := termboxUI.CreateButton(5, 3, text, termbox.ColorDefault, termbox.ColorDefault)
+			want := Snapshot(RenderField(button, 0, 0, 5, 3))
+			t.Parallel()
+			for i := 0; i < 50; i++ {
+				if got := Snapshot(RenderField(button, 0, 0, 5, 3)); got != want {
+					t.Fatalf("rendering in parallel gave\n%s\nwant\n%s", got, want)
+				}
+			}
+		})
+	}
+}
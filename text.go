@@ -1,9 +1,6 @@
 package termboxUI
 
-//TODO: text box doesn't parse `\n\n` correctly. All text following these characters is ignored.
-
 import (
-	"bufio"
 	"io"
 	"strings"
 
@@ -18,7 +15,7 @@ import (
 func FillArea(x, y, w, h int, fg, bg termbox.Attribute) {
 	for row := 0; row < h; row++ {
 		for column := 0; column < w; column++ {
-			termbox.SetCell(x+column, y+row, ' ', fg, bg)
+			activeScreen.SetCell(x+column, y+row, ' ', fg, bg)
 		}
 	}
 	return
@@ -28,7 +25,7 @@ func FillArea(x, y, w, h int, fg, bg termbox.Attribute) {
 // Cells 'x' and 'w' are included.
 func DrawHorizontalLine(x, y, w int, fg, bg termbox.Attribute) {
 	for i := 0; i <= w; i++ {
-		termbox.SetCell(x+i, y, '─', fg, bg)
+		activeScreen.SetCell(x+i, y, '─', fg, bg)
 	}
 	return
 }
@@ -37,7 +34,7 @@ func DrawHorizontalLine(x, y, w int, fg, bg termbox.Attribute) {
 // Cells 'y' and 'h' are included.
 func DrawVerticalLine(x, y, h int, fg, bg termbox.Attribute) {
 	for i := 0; i <= h; i++ {
-		termbox.SetCell(x, y+i, '│', fg, bg)
+		activeScreen.SetCell(x, y+i, '│', fg, bg)
 	}
 	return
 }
@@ -45,14 +42,14 @@ func DrawVerticalLine(x, y, h int, fg, bg termbox.Attribute) {
 // Like FillArea, but it also draws a border around the area using the 'fg' attribute as the color.
 func DrawRectangle(x, y, h, w int, fg, bg termbox.Attribute) {
 	FillArea(x, y, w, h, fg, bg)
-	DrawHorizontalLine(x, y, w, fg, bg)    // top
-	DrawHorizontalLine(x, h+y, w, fg, bg)  // bottom
-	DrawVerticalLine(x, y, h, fg, bg)      // left
-	DrawVerticalLine(x+w, y, h, fg, bg)    // right
-	termbox.SetCell(x, y, '┌', fg, bg)     // top-left corner
-	termbox.SetCell(x+w, y, '┐', fg, bg)   // top-right corner
-	termbox.SetCell(x, h+y, '└', fg, bg)   // bottom-left corner
-	termbox.SetCell(x+w, h+y, '┘', fg, bg) // bottom-right corner
+	DrawHorizontalLine(x, y, w, fg, bg)         // top
+	DrawHorizontalLine(x, h+y, w, fg, bg)       // bottom
+	DrawVerticalLine(x, y, h, fg, bg)           // left
+	DrawVerticalLine(x+w, y, h, fg, bg)         // right
+	activeScreen.SetCell(x, y, '┌', fg, bg)     // top-left corner
+	activeScreen.SetCell(x+w, y, '┐', fg, bg)   // top-right corner
+	activeScreen.SetCell(x, h+y, '└', fg, bg)   // bottom-left corner
+	activeScreen.SetCell(x+w, h+y, '┘', fg, bg) // bottom-right corner
 }
 
 //======================================================//
@@ -61,18 +58,24 @@ func DrawRectangle(x, y, h, w int, fg, bg termbox.Attribute) {
 
 // This is the most basic text drawing function.
 // It writes a single line of text to the terminal with the specified settings.
+// Characters are placed by their display width, so wide characters take up two cells. The returned x coordinate is the cell after the text.
 func DrawText(x, y int, line string, fg, bg termbox.Attribute) (int, int) {
-	for i, ch := range line {
-		termbox.SetCell(x+i, y, ch, fg, bg)
+	for len(line) > 0 {
+		ch, width, size := nextGrapheme(line)
+		if width > 0 {
+			activeScreen.SetCell(x, y, ch, fg, bg)
+		}
+		x += width
+		line = line[size:]
 	}
-	return x + len(line), y
+	return x, y
 }
 
 // This returns the termbox x coordinate to center the given string within the described area.
 // That coordinate value returned should be referenced before drawing the text.
 // Note that this doesn't actually draw the text string to the terminal.
 func HorizontalCenterString(text string, dimension, offset int) int {
-	return (dimension-len(text))/2 + offset
+	return (dimension-StringWidth(text))/2 + offset
 }
 
 //======================================================//
@@ -93,6 +96,11 @@ const (
 
 // Basic text box for displaying text in a termbox window.
 // HasBorder indicates that the border around the text box should be included when drawing. Note that the borders are drawn within the defined text box's area, effectively losing two columns and two rows of text writing area.
+// With Follow set, the text box scrolls along as lines are added, unless the user has scrolled up from the bottom.
+// MaxLines limits the number of lines that are kept; once it is reached, adding a line drops the oldest one. 0 keeps every line.
+// CustomType is used for the error event sent when reading from AddTextFrom fails.
+// ShowScrollbar gives up the rightmost column of the text area to a scrollbar that shows which part of the text is in view.
+// SearchRegexp and SearchIgnoreCase change how the text typed at the '/' search prompt is matched.
 type TextBox struct {
 	HasBorder                   bool
 	WrapText                    bool
@@ -102,21 +110,34 @@ type TextBox struct {
 	Height                      int
 	Default_fg                  termbox.Attribute
 	Default_bg                  termbox.Attribute
-
-	text        []string
-	textHeight  int
+	ANSI                        ANSIParser // the styles of text read by AddTextFrom
+	Follow                      bool
+	MaxLines                    int
+	CustomType                  uint16
+	ShowScrollbar               bool
+	SearchRegexp                bool
+	SearchIgnoreCase            bool
+
+	text        lineRing
 	activeIndex int
-	scrolling   bool
-	reader      io.Reader
+	scrollX     int
+	search      textSearch
+	reader      io.Reader   // given to AddTextFrom and not yet being read
+	stream      *textStream // the lines read from the reader
+
+	source    lineRing // the text as it was added, before wrapping
+	wrapWidth int      // the width the text was last wrapped to, or 0 if it wasn't wrapped
+	longest   int      // the width of the longest displayed line
 }
 
 // This will create a new text box definition.
 // If the width or height exceed the dimensions of the termbox, then the screen dimension will be used in place of 'width' or 'height'
+// Before the screen is initialized it has no dimensions, so only -1 is replaced at that point.
 func CreateTextBox(width, height int, withBorder, wrapText bool, justification_h, justification_v uint16, fg, bg termbox.Attribute) *TextBox {
 	textbox := new(TextBox)
-	screenWidth, screenHeight := termbox.Size()
+	screenWidth, screenHeight := activeScreen.Size()
 
-	if width == -1 || width > screenWidth {
+	if width == -1 || (screenWidth > 0 && width > screenWidth) {
 		textbox.Width = screenWidth
 	} else {
 		textbox.Width = width
@@ -137,102 +158,232 @@ func CreateTextBox(width, height int, withBorder, wrapText bool, justification_h
 	textbox.HasBorder = withBorder
 	textbox.WrapText = wrapText
 
-	newHeight := textbox.Height
-	if textbox.HasBorder && textbox.Height > 2 {
-		newHeight -= 2
-	}
-	textbox.text = make([]string, newHeight)
-	textbox.textHeight = 0
 	textbox.activeIndex = 0
-	textbox.scrolling = true
 	textbox.reader = nil
+	textbox.wrapWidth = textbox.currentWrapWidth()
 
 	return textbox
 }
 
 // This lets a text box accept a reader instead of an explicit string.
 // The assumption is that the type of data from the read source is always 'string', at least for now...
+// ANSI color and style sequences in the text, such as those in the output of a command, are drawn as styled text.
+// Once the text box is drawn by a UI, the reader is read on a goroutine of its own and each line is added and drawn as it
+// arrives, wherever in the UI the text box is drawn from. A text box given a reader that another text box of the UI is
+// already reading, such as after StartUI rebuilds the UI, takes over the lines read from then on.
+// Reading stops at the end of the input or when the UI stops, and a reader that is also an io.Closer is closed when the
+// UI stops. A read error is sent to the UI as an error event with the text box's CustomType.
 func (tb *TextBox) AddTextFrom(strReader io.Reader) error {
 	tb.reader = strReader
+	tb.stream = nil
 	return nil
 }
 
 // This adds a single line of text to the text box.
 // The '\n' rune is translated to a new line and the '\t' rune is treated as four spaces.
+// When WrapText is set, long lines are wrapped at word boundaries to the width of the text box.
 func (tb *TextBox) AddText(text string) {
-	height := tb.Height
+	tb.AddStyledText(Span{Text: text})
+}
+
+// AddMarkup adds text that is styled with markup tags such as "[red::b]", see ParseMarkup.
+// It is handled the same way as by AddText otherwise. Use EscapeMarkup for parts that should be shown as they are.
+func (tb *TextBox) AddMarkup(text string) {
+	tb.AddStyledText(ParseMarkup(text)...)
+}
+
+// Add text that is either plain or marked up, for the fields that have a Markup setting.
+func (tb *TextBox) addText(text string, markup bool) {
+	if markup {
+		tb.AddMarkup(text)
+		return
+	}
+	tb.AddText(text)
+}
+
+// This adds styled text to the text box, such as a highlighted keyword within a line.
+// The text is handled the same way as by AddText. Wrapping and alignment apply to the line as a whole, across its spans.
+func (tb *TextBox) AddStyledText(spans ...Span) {
+	if tb.wrapWidth != tb.currentWrapWidth() {
+		tb.rewrap()
+	}
+
+	for _, line := range splitSpanLines(spans) {
+
+		for i := range line {
+			line[i].Text = strings.Replace(line[i].Text, "\t", "    ", -1)
+		}
+
+		tb.source.push(line)
+		tb.appendLines(tb.wrapLine(line))
+	}
+
+	tb.evictLines()
+}
+
+// The width available to the text inside the border and next to the scrollbar.
+func (tb *TextBox) textWidth() int {
 	width := tb.Width
+	if tb.HasBorder {
+		width -= 2
+	}
+	if tb.ShowScrollbar {
+		width--
+	}
+	return width
+}
+
+// The width that added text is wrapped to, or 0 when WrapText is not set.
+func (tb *TextBox) currentWrapWidth() int {
+	if !tb.WrapText {
+		return 0
+	}
+	return tb.textWidth()
+}
+
+// Break a line of added text into the lines displayed by the text box.
+func (tb *TextBox) wrapLine(line []Span) [][]Span {
+	return wrapSpans(line, tb.currentWrapWidth())
+}
 
+// The number of lines that fit inside the border and above the search line.
+func (tb *TextBox) textHeight() int {
+	height := tb.Height
 	if tb.HasBorder {
 		height -= 2
-		width -= 2
 	}
+	if tb.searchActive() {
+		height--
+	}
+	return height
+}
 
-	var lines []string
-	linesHeight := 0
-	strArray := strings.Split(text, "\n")
-	for _, line := range strArray {
+// The largest scroll position, where the last line is at the bottom of the text box.
+func (tb *TextBox) maxScroll() int {
+	if scroll := tb.text.len() - tb.textHeight(); scroll > 0 {
+		return scroll
+	}
+	return 0
+}
 
-		line = strings.Replace(line, "\t", "    ", -1)
+// The largest horizontal scroll position, where the end of the longest line is at the right edge of the text box.
+func (tb *TextBox) maxScrollX() int {
+	if scroll := tb.longest - tb.textWidth(); scroll > 0 {
+		return scroll
+	}
+	return 0
+}
 
-		if len(line) == 0 {
-			break
+// Add a line to the displayed text, keeping track of the longest one.
+func (tb *TextBox) pushLine(line []Span) {
+	tb.text.push(line)
+	if width := spansWidth(line); width > tb.longest {
+		tb.longest = width
+	}
+}
+
+// Find the longest displayed line again, after it may have been dropped.
+func (tb *TextBox) measureLongest() {
+	tb.longest = 0
+	for i := 0; i < tb.text.len(); i++ {
+		if width := spansWidth(tb.text.at(i)); width > tb.longest {
+			tb.longest = width
+		}
+	}
+}
+
+// Move the text so that 'row' is the top line in view, within the bounds of the text.
+func (tb *TextBox) scrollTo(row int) {
+	if row > tb.maxScroll() {
+		row = tb.maxScroll()
+	}
+	if row < 0 {
+		row = 0
+	}
+	tb.activeIndex = row
+}
+
+// Add displayed lines to the end of the text.
+// In follow mode, a text box that shows the last line scrolls down to keep showing it.
+func (tb *TextBox) appendLines(lines [][]Span) {
+	following := tb.Follow && tb.activeIndex >= tb.maxScroll()
+	for _, line := range lines {
+		tb.pushLine(line)
+	}
+	tb.search.stale = true
+	if following {
+		tb.activeIndex = tb.maxScroll()
+	}
+	tb.wrapWidth = tb.currentWrapWidth()
+}
+
+// Drop the oldest lines of added text beyond MaxLines, along with the lines they were wrapped into.
+// The lines that are shown don't move, unless they are the ones being dropped.
+func (tb *TextBox) evictLines() {
+	if tb.MaxLines <= 0 {
+		return
+	}
+
+	droppedLongest := false
+	for tb.source.len() > tb.MaxLines {
+		rows := len(wrapText(spansText(tb.source.at(0)), tb.wrapWidth))
+		for i := 0; i < rows && i < tb.text.len(); i++ {
+			droppedLongest = droppedLongest || spansWidth(tb.text.at(i)) == tb.longest
 		}
+		tb.source.dropFront(1)
+		tb.text.dropFront(rows)
+		tb.search.stale = true
 
-		if tb.WrapText && len(line) > width {
-			for len(line) != 0 {
-				var newLine = ""
-
-				if len(line) < width {
-					newLine = line
-					line = ""
-				} else {
-					newLine = line[:width-1]
-					line = line[width:]
-				}
-
-				if !tb.scrolling && linesHeight+tb.textHeight <= height {
-					lines = append(lines, newLine)
-					linesHeight++
-				} else {
-					break
-				}
-			}
-		} else {
-			if !tb.scrolling && linesHeight+tb.textHeight <= height {
-				lines = append(lines, line)
-				linesHeight++
-			} else {
-				lines = append(lines, line)
-				linesHeight++
-			}
+		tb.activeIndex -= rows
+		if tb.activeIndex < 0 {
+			tb.activeIndex = 0
 		}
 	}
+	// Only dropping the longest line means measuring the rest again.
+	if droppedLongest {
+		tb.measureLongest()
+	}
+}
+
+// Wrap all of the added text again, for instance after the width of the text box has changed on a resize.
+// The line of added text shown at the top of the text box stays at the top, or the bottom stays in view when following.
+func (tb *TextBox) rewrap() {
+	following := tb.Follow && tb.activeIndex >= tb.maxScroll()
 
-	if linesHeight > 0 {
-		temp := make([]string, linesHeight+tb.textHeight)
-		copy(temp, tb.text)
-		copy(temp[tb.textHeight:], lines)
-		tb.text = temp
+	top, row := 0, 0
+	for top < tb.source.len() {
+		rows := len(wrapText(spansText(tb.source.at(top)), tb.wrapWidth))
+		if row+rows > tb.activeIndex {
+			break
+		}
+		row += rows
+		top++
+	}
 
-		tb.textHeight += linesHeight
+	tb.text.reset()
+	tb.longest = 0
+	tb.activeIndex = 0
+	for i := 0; i < tb.source.len(); i++ {
+		if i == top {
+			tb.activeIndex = tb.text.len()
+		}
+		for _, line := range tb.wrapLine(tb.source.at(i)) {
+			tb.pushLine(line)
+		}
+	}
+	if following || tb.activeIndex > tb.maxScroll() {
+		tb.activeIndex = tb.maxScroll()
 	}
+	tb.wrapWidth = tb.currentWrapWidth()
 }
 
 // This will write the text box to the terminal. 'x' and 'y' are the upper-left coordinates from which the box will be drawn.
 // The cell at that location is included when drawing.
 // If the number of lines of the text box after wrapping is applied is larger than the height of the box, scrolling is automatically applied.
 func (tb *TextBox) Draw(x, y int) {
-
-	if tb.reader != nil {
-		reader := bufio.NewReader(tb.reader)
-		str, err := reader.ReadString('\n')
-		if err != nil && err != io.EOF {
-			panic(err)
-		}
-		if len(str) > 0 {
-			tb.AddText(str)
-		}
+	tb.takeStreamedText()
+	if tb.wrapWidth != tb.currentWrapWidth() {
+		tb.rewrap()
 	}
 
 	width := tb.Width
@@ -248,55 +399,122 @@ func (tb *TextBox) Draw(x, y int) {
 		FillArea(x, y, width, height, tb.Default_fg, tb.Default_bg)
 	}
 
-	for i := 0; i <= height; i++ {
-		if i+tb.activeIndex > tb.textHeight {
-			break
+	if tb.searchActive() {
+		height--
+		if tb.search.stale {
+			tb.findMatches(false)
 		}
+		tb.drawSearchLine(x, y+height, width)
+	}
+
+	if tb.ShowScrollbar {
+		width--
+		tb.drawScrollbar(x+width, y, height)
+	}
+	if tb.scrollX > tb.maxScrollX() {
+		tb.scrollX = tb.maxScrollX()
+	}
 
-		if tb.activeIndex+i == len(tb.text) {
+	for i := 0; i < height; i++ {
+		if tb.activeIndex+i >= tb.text.len() {
 			break
 		}
 		var x_coord, y_coord int
 
-		line := tb.text[tb.activeIndex+i]
+		line := tb.highlightMatches(tb.activeIndex+i, tb.text.at(tb.activeIndex+i))
 
+		// Lines are positioned relative to the left edge of the text area, and then shifted by the horizontal scroll.
 		switch tb.TextHorizontalJustification {
 		case TextAlignmentCenter:
-			x_coord = HorizontalCenterString(line, width, x)
+			x_coord = (width - spansWidth(line)) / 2
 		case TextAlignmentRight:
-			x_coord = (x + width) - len(line) - 1
+			x_coord = width - spansWidth(line)
 		default:
-			x_coord = x
+			x_coord = 0
 		}
 
 		switch tb.TextVerticalJustification {
 		case TextAlignmentCenter:
 			y_coord = y + (height / 2) + i
 		case TextAlignmentBottom:
-			y_coord = (y + height) - tb.textHeight + i
+			y_coord = (y + height) - tb.text.len() + i
 		default:
 			y_coord = y + i
 		}
 
-		DrawText(x_coord, y_coord, line, tb.Default_fg, tb.Default_bg)
+		drawSpansClipped(x, y_coord, width, tb.scrollX-x_coord, line, tb.Default_fg, tb.Default_bg)
+	}
+}
+
+// Draw the scrollbar in the column at x. The thumb covers the part of the text that is in view.
+func (tb *TextBox) drawScrollbar(x, y, height int) {
+	if height <= 0 {
+		return
+	}
+
+	thumbSize, thumbTop := height, 0
+	if total := tb.text.len(); total > height {
+		thumbSize = height * height / total
+		if thumbSize < 1 {
+			thumbSize = 1
+		}
+		thumbTop = tb.activeIndex * (height - thumbSize) / tb.maxScroll()
+	}
+
+	for i := 0; i < height; i++ {
+		ch := '░'
+		if i >= thumbTop && i < thumbTop+thumbSize {
+			ch = '█'
+		}
+		activeScreen.SetCell(x, y+i, ch, tb.Default_fg, tb.Default_bg)
 	}
 }
 
 // Handle the termbox key or character input.
-// The up and down keys will scroll the text within the text box area.
+// The up and down keys will scroll the text within the text box area, and 'PageUp' and 'PageDown' scroll it by a whole page.
+// 'Home' and 'End' jump to the top and the bottom of the text.
+// When the text isn't wrapped, the left and right keys scroll it sideways to show the rest of long lines.
+// '/' opens a prompt to search the text, and 'n' and 'N' then move between the matches.
 // Any other input is ignored by text box.
 func (tb *TextBox) HandleKey(key termbox.Key, ch rune, results chan UIEvent) bool {
+	if tb.handleSearchKey(key, ch) {
+		return true
+	}
+
 	eventConsumed := true
 
+	page := tb.textHeight() - 1
+	if page < 1 {
+		page = 1
+	}
+
 	switch key {
 	case termbox.KeyArrowUp:
-		tb.activeIndex--
-		if tb.activeIndex < 0 {
-			tb.activeIndex = 0
-		}
+		tb.scrollTo(tb.activeIndex - 1)
 	case termbox.KeyArrowDown:
-		if !(tb.activeIndex+tb.Height >= tb.textHeight+1) {
-			tb.activeIndex++
+		tb.scrollTo(tb.activeIndex + 1)
+	case termbox.KeyPgup:
+		tb.scrollTo(tb.activeIndex - page)
+	case termbox.KeyPgdn:
+		tb.scrollTo(tb.activeIndex + page)
+	case termbox.KeyHome:
+		tb.scrollTo(0)
+		tb.scrollX = 0
+	case termbox.KeyEnd:
+		tb.scrollTo(tb.maxScroll())
+	case termbox.KeyArrowLeft:
+		if tb.WrapText {
+			return false
+		}
+		if tb.scrollX > 0 {
+			tb.scrollX--
+		}
+	case termbox.KeyArrowRight:
+		if tb.WrapText {
+			return false
+		}
+		if tb.scrollX < tb.maxScrollX() {
+			tb.scrollX++
 		}
 	default:
 		eventConsumed = false
@@ -304,3 +522,69 @@ func (tb *TextBox) HandleKey(key termbox.Key, ch rune, results chan UIEvent) boo
 
 	return eventConsumed
 }
+
+// The size of the area the text box is drawn in.
+func (tb *TextBox) Size() (width, height int) {
+	return tb.Width, tb.Height
+}
+
+// Handles mouse input.
+// The mouse wheel scrolls the text like the up and down keys.
+func (tb *TextBox) HandleMouse(x, y int, key termbox.Key, results chan UIEvent) bool {
+	switch key {
+	case termbox.MouseWheelUp:
+		return tb.HandleKey(termbox.KeyArrowUp, 0, results)
+	case termbox.MouseWheelDown:
+		return tb.HandleKey(termbox.KeyArrowDown, 0, results)
+	default:
+		return false
+	}
+}
+
+// A ring buffer of lines, which grows as needed. Dropping lines from the front doesn't move the other lines.
+type lineRing struct {
+	lines [][]Span
+	start int
+	count int
+}
+
+func (r *lineRing) len() int {
+	return r.count
+}
+
+// The line at 'index', counting from the oldest line.
+func (r *lineRing) at(index int) []Span {
+	return r.lines[(r.start+index)%len(r.lines)]
+}
+
+// Add a line after the newest line.
+func (r *lineRing) push(line []Span) {
+	if r.count == len(r.lines) {
+		lines := make([][]Span, 2*r.count+1)
+		for i := 0; i < r.count; i++ {
+			lines[i] = r.at(i)
+		}
+		r.lines = lines
+		r.start = 0
+	}
+	r.lines[(r.start+r.count)%len(r.lines)] = line
+	r.count++
+}
+
+// Remove the 'n' oldest lines.
+func (r *lineRing) dropFront(n int) {
+	if n > r.count {
+		n = r.count
+	}
+	for i := 0; i < n; i++ {
+		r.lines[(r.start+i)%len(r.lines)] = nil
+	}
+	if len(r.lines) > 0 {
+		r.start = (r.start + n) % len(r.lines)
+	}
+	r.count -= n
+}
+
+func (r *lineRing) reset() {
+	r.dropFront(r.count)
+}
@@ -0,0 +1,365 @@
+package termboxUI
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/nsf/termbox-go"
+)
+
+//============================//
+//         Text Area          //
+//----------------------------//
+
+// A text area is a multi-line edit box. 'Enter' starts a new line, and the text is sent as a string event with the
+// custom type when the SubmitKey is pressed. The text scrolls to keep the cursor in view, and is never changed by drawing.
+// With WrapText set, lines that are too long for the text area continue on the next row, breaking after a space where possible.
+// Otherwise the text scrolls sideways.
+// Text pasted into the terminal is inserted as it is, line breaks included, without being submitted.
+type TextArea struct {
+	Width       int
+	Height      int
+	Value       []rune
+	Fg          termbox.Attribute
+	Bg          termbox.Attribute
+	CursorIndex int
+	CustomType  uint16
+	WrapText    bool
+	SubmitKey   termbox.Key // KeyCtrlEnter by default, which also accepts the KeyCtrlJ that most terminals send for Ctrl+Enter.
+
+	focused    bool
+	scrollX    int
+	scrollY    int
+	goalColumn int  // the column that up and down try to keep the cursor in
+	atRowEnd   bool // the cursor is at the end of a wrapped row rather than at the start of the next one
+	pasting    bool
+	pasted     []rune
+}
+
+// A row of the text area on screen, as rune indices into the value. The newline that ends a line is not part of any row.
+type textAreaRow struct {
+	start, end int
+}
+
+// Creates a new instance of a text area.
+// When width or height is -1, the text area will be the width or height of the terminal window.
+func CreateTextArea(width, height int, value string, wrapText bool, customMessageCode uint16, fg, bg termbox.Attribute) *TextArea {
+	textArea := new(TextArea)
+
+	screenWidth, screenHeight := activeScreen.Size()
+
+	textArea.Width = width
+	if width == -1 {
+		textArea.Width = screenWidth
+	}
+	textArea.Height = height
+	if height == -1 {
+		textArea.Height = screenHeight
+	}
+
+	textArea.Fg = fg
+	textArea.Bg = bg
+	textArea.WrapText = wrapText
+	textArea.CustomType = customMessageCode
+	textArea.SubmitKey = KeyCtrlEnter
+
+	textArea.Value = []rune(value)
+	textArea.CursorIndex = 0
+
+	return textArea
+}
+
+func (ta *TextArea) Draw(x, y int) {
+	rows := ta.layout()
+	row, column := ta.cursorPosition(rows)
+	ta.scrollToCursor(rows, row, column)
+
+	FillArea(x, y, ta.Width, ta.Height, ta.Fg, ta.Bg)
+	for i := 0; i < ta.Height && ta.scrollY+i < len(rows); i++ {
+		r := rows[ta.scrollY+i]
+		line := []Span{{Text: string(ta.Value[r.start:r.end])}}
+		drawSpansClipped(x, y+i, ta.Width, ta.scrollX, line, ta.Fg, ta.Bg)
+	}
+
+	if ta.focused {
+		activeScreen.SetCursor(x+column-ta.scrollX, y+row-ta.scrollY)
+	}
+}
+
+// Show the cursor in the text area while it has focus.
+func (ta *TextArea) HandleFocus(focused bool) {
+	ta.focused = focused
+}
+
+// 'Tab' is typed into the text area instead of moving the focus, which 'Shift+Tab' still does.
+// During a bracketed paste all keys go to the text area.
+func (ta *TextArea) ClaimsKey(key termbox.Key, ch rune) bool {
+	return ta.pasting || key == termbox.KeyTab
+}
+
+// Handles a termbox key or character input
+// The SubmitKey sends the text and clears the text area. 'Enter' starts a new line.
+// The arrow keys move the cursor, and 'Home' and 'End' move it to the start and end of the row.
+// 'PageUp' and 'PageDown' move the cursor by the height of the text area.
+// 'Backspace' and 'Delete' remove a character along with any combining marks, 'Tab' inserts four spaces and anything else is added to the text.
+func (ta *TextArea) HandleKey(key termbox.Key, ch rune, ev chan UIEvent) (eventConsumed bool) {
+	eventConsumed = true
+
+	if ta.CursorIndex > len(ta.Value) {
+		ta.CursorIndex = len(ta.Value)
+	}
+
+	if ta.pasting {
+		ta.handlePasteKey(key, ch)
+		return
+	}
+	if key == KeyPasteStart {
+		ta.pasting = true
+		return
+	}
+
+	if key == ta.SubmitKey || ta.SubmitKey == KeyCtrlEnter && key == termbox.KeyCtrlJ {
+		ev <- NewStringEvent(ta.CustomType, string(ta.Value))
+
+		ta.Value = make([]rune, 0)
+		ta.CursorIndex = 0
+		ta.goalColumn = 0
+		ta.atRowEnd = false
+		return
+	}
+
+	ta.atRowEnd = false
+
+	switch key {
+	case termbox.KeyArrowUp:
+		ta.moveVertically(-1)
+		return
+	case termbox.KeyArrowDown:
+		ta.moveVertically(1)
+		return
+	case termbox.KeyPgup:
+		ta.moveVertically(-ta.Height)
+		return
+	case termbox.KeyPgdn:
+		ta.moveVertically(ta.Height)
+		return
+	case termbox.KeyHome:
+		rows := ta.layout()
+		row, _ := ta.cursorPosition(rows)
+		ta.CursorIndex = rows[row].start
+	case termbox.KeyEnd:
+		rows := ta.layout()
+		row, _ := ta.cursorPosition(rows)
+		ta.CursorIndex = rows[row].end
+		ta.atRowEnd = true
+	case termbox.KeyArrowLeft:
+		ta.CursorIndex = previousClusterIndex(ta.Value, ta.CursorIndex)
+	case termbox.KeyArrowRight:
+		ta.CursorIndex = nextClusterIndex(ta.Value, ta.CursorIndex)
+	case termbox.KeyEnter:
+		ta.insert('\n')
+	case termbox.KeyTab:
+		ta.insert(' ', ' ', ' ', ' ')
+	case termbox.KeySpace:
+		ta.insert(' ')
+	case termbox.KeyBackspace, termbox.KeyBackspace2:
+		ta.remove(previousClusterIndex(ta.Value, ta.CursorIndex), ta.CursorIndex)
+	case termbox.KeyDelete:
+		ta.remove(ta.CursorIndex, nextClusterIndex(ta.Value, ta.CursorIndex))
+	default:
+		if ch != 0 {
+			ta.insert(ch)
+		} else {
+			eventConsumed = false
+		}
+	}
+
+	// Up and down aim for the column the cursor was left in by anything else.
+	_, ta.goalColumn = ta.cursorPosition(ta.layout())
+	return
+}
+
+// The size of the area the text area is drawn in.
+func (ta *TextArea) Size() (width, height int) {
+	return ta.Width, ta.Height
+}
+
+// A left click moves the cursor to the clicked character, and the mouse wheel moves it up and down.
+func (ta *TextArea) HandleMouse(x, y int, key termbox.Key, ev chan UIEvent) bool {
+	switch key {
+	case termbox.MouseWheelUp:
+		ta.moveVertically(-1)
+	case termbox.MouseWheelDown:
+		ta.moveVertically(1)
+	case termbox.MouseLeft:
+		rows := ta.layout()
+		row := ta.scrollY + y
+		if row >= len(rows) {
+			ta.CursorIndex = len(ta.Value)
+			ta.atRowEnd = false
+		} else {
+			r := rows[row]
+			ta.CursorIndex = r.start + indexOfColumn(ta.Value[r.start:r.end], x+ta.scrollX)
+			ta.atRowEnd = ta.CursorIndex == r.end
+		}
+		_, ta.goalColumn = ta.cursorPosition(rows)
+	default:
+		return false
+	}
+	return true
+}
+
+// Collect the keys of a bracketed paste, and insert the text once the paste ends.
+// Line breaks are kept as they are rather than submitting the text, and tabs become four spaces like the 'Tab' key.
+func (ta *TextArea) handlePasteKey(key termbox.Key, ch rune) {
+	switch key {
+	case KeyPasteEnd:
+		ta.pasting = false
+		text := strings.NewReplacer("\r\n", "\n", "\r", "\n", "\t", "    ").Replace(string(ta.pasted))
+		ta.pasted = nil
+		ta.atRowEnd = false
+		ta.insert([]rune(text)...)
+		_, ta.goalColumn = ta.cursorPosition(ta.layout())
+	case termbox.KeyEnter:
+		ta.pasted = append(ta.pasted, '\r')
+	case termbox.KeyCtrlJ:
+		ta.pasted = append(ta.pasted, '\n')
+	case termbox.KeyTab:
+		ta.pasted = append(ta.pasted, '\t')
+	case termbox.KeySpace:
+		ta.pasted = append(ta.pasted, ' ')
+	default:
+		if ch != 0 {
+			ta.pasted = append(ta.pasted, ch)
+		}
+	}
+}
+
+// Insert characters at the cursor and move the cursor past them.
+func (ta *TextArea) insert(chs ...rune) {
+	value := make([]rune, 0, len(ta.Value)+len(chs))
+	value = append(value, ta.Value[:ta.CursorIndex]...)
+	value = append(value, chs...)
+	ta.Value = append(value, ta.Value[ta.CursorIndex:]...)
+	ta.CursorIndex += len(chs)
+}
+
+// Remove the characters between two indices, such as a whole cluster with its combining marks, and leave the cursor at the start.
+func (ta *TextArea) remove(start, end int) {
+	ta.Value = append(ta.Value[:start:start], ta.Value[end:]...)
+	ta.CursorIndex = start
+}
+
+// Move the cursor up or down by a number of rows, staying as close to the goal column as the row allows.
+func (ta *TextArea) moveVertically(delta int) {
+	rows := ta.layout()
+	row, _ := ta.cursorPosition(rows)
+
+	row += delta
+	if row < 0 {
+		row = 0
+	}
+	if row >= len(rows) {
+		row = len(rows) - 1
+	}
+
+	r := rows[row]
+	ta.CursorIndex = r.start + indexOfColumn(ta.Value[r.start:r.end], ta.goalColumn)
+	ta.atRowEnd = ta.CursorIndex == r.end
+}
+
+// Split the value into the rows that are shown on screen.
+func (ta *TextArea) layout() []textAreaRow {
+	var rows []textAreaRow
+	start := 0
+	for i := 0; i <= len(ta.Value); i++ {
+		if i == len(ta.Value) || ta.Value[i] == '\n' {
+			rows = append(rows, ta.wrapLine(start, i)...)
+			start = i + 1
+		}
+	}
+	return rows
+}
+
+// Split a line of the value into rows. Without WrapText, the line is a single row.
+// One column is kept free so that the cursor fits after the last character of a row.
+func (ta *TextArea) wrapLine(start, end int) []textAreaRow {
+	width := ta.Width - 1
+	if !ta.WrapText || width < 1 {
+		return []textAreaRow{{start, end}}
+	}
+
+	var rows []textAreaRow
+	line := ta.Value[:end]
+	for {
+		// Find how much of the line fits, and the last place to break after a space.
+		fit, used, breakAt := start, 0, -1
+		for fit < end {
+			next := nextClusterIndex(line, fit)
+			clusterWidth := RunesWidth(line[fit:next])
+			if used+clusterWidth > width {
+				break
+			}
+			used += clusterWidth
+			if unicode.IsSpace(line[fit]) {
+				breakAt = next
+			}
+			fit = next
+		}
+
+		if fit == end {
+			return append(rows, textAreaRow{start, end})
+		}
+		if breakAt > start {
+			fit = breakAt
+		}
+		if fit == start {
+			fit = nextClusterIndex(line, start)
+		}
+		rows = append(rows, textAreaRow{start, fit})
+		start = fit
+	}
+}
+
+// The row and column of the cursor.
+// A cursor between two rows of a wrapped line is placed at the start of the second, unless it was moved to the end
+// of the first, such as with 'End'.
+func (ta *TextArea) cursorPosition(rows []textAreaRow) (row, column int) {
+	if ta.CursorIndex > len(ta.Value) {
+		ta.CursorIndex = len(ta.Value)
+	}
+	if ta.CursorIndex < 0 {
+		ta.CursorIndex = 0
+	}
+
+	row = sort.Search(len(rows), func(i int) bool { return rows[i].start > ta.CursorIndex }) - 1
+	if ta.atRowEnd && row > 0 && rows[row-1].end == ta.CursorIndex {
+		row--
+	}
+	column = RunesWidth(ta.Value[rows[row].start:ta.CursorIndex])
+	return
+}
+
+// Scroll just far enough for the cursor to be in view.
+func (ta *TextArea) scrollToCursor(rows []textAreaRow, row, column int) {
+	if maxScrollY := len(rows) - ta.Height; ta.scrollY > maxScrollY {
+		ta.scrollY = maxScrollY
+	}
+	if ta.scrollY < 0 {
+		ta.scrollY = 0
+	}
+	if row >= ta.scrollY+ta.Height {
+		ta.scrollY = row - ta.Height + 1
+	}
+	if row < ta.scrollY {
+		ta.scrollY = row
+	}
+
+	if column >= ta.scrollX+ta.Width {
+		ta.scrollX = column - ta.Width + 1
+	}
+	if column < ta.scrollX {
+		ta.scrollX = column
+	}
+}
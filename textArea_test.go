@@ -0,0 +1,83 @@
+package termboxUI
+
+import (
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestTextAreaCursorStaysAtTheEndOfAWrappedRow(t *testing.T) {
+	screen := NewSimulationScreen(20, 5)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	area := CreateTextArea(5, 3, "abcdefgh", true, 0, termbox.ColorDefault, termbox.ColorDefault)
+	area.HandleFocus(true)
+	ev := make(chan UIEvent, 1)
+
+	area.HandleKey(termbox.KeyEnd, 0, ev)
+	area.Draw(0, 0)
+	if x, y := screen.Cursor(); x != 4 || y != 0 || area.CursorIndex != 4 {
+		t.Errorf("after End the cursor is at %d,%d (index %d), want the end of the first row", x, y, area.CursorIndex)
+	}
+
+	area.HandleKey(termbox.KeyArrowLeft, 0, ev)
+	area.HandleKey(termbox.KeyArrowRight, 0, ev)
+	area.Draw(0, 0)
+	if x, y := screen.Cursor(); x != 0 || y != 1 {
+		t.Errorf("after moving right the cursor is at %d,%d, want the start of the second row", x, y)
+	}
+}
+
+func TestTextAreaTypesTab(t *testing.T) {
+	screen := NewSimulationScreen(20, 5)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	ui := new(UI)
+	area := CreateTextArea(10, 3, "", false, 0, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(area, 0, 0, true)
+	ui.AddField(CreateEditBox(10, "", 0, termbox.ColorDefault, termbox.ColorDefault), 0, 3, false)
+
+	ui.FeedInput(make(chan UIEvent, 1), KeyEvent(termbox.KeyTab, 0))
+	if string(area.Value) != "    " {
+		t.Errorf("Tab left %q in the text area", string(area.Value))
+	}
+	if field := ui.FocusedField(); field == nil || field.Element != area {
+		t.Error("Tab moved the focus away from the text area")
+	}
+
+	ui.FeedInput(make(chan UIEvent, 1), KeyEvent(KeyBacktab, 0))
+	if field := ui.FocusedField(); field != nil && field.Element == area {
+		t.Error("Shift+Tab did not move the focus away from the text area")
+	}
+}
+
+func TestTextAreaPasteKeepsLineBreaks(t *testing.T) {
+	area := CreateTextArea(20, 3, "", false, 0, termbox.ColorDefault, termbox.ColorDefault)
+	ev := make(chan UIEvent, 1)
+
+	area.HandleKey(KeyPasteStart, 0, ev)
+	if !area.ClaimsKey(termbox.KeyEsc, 0) {
+		t.Error("keys are not claimed during a paste")
+	}
+	for _, key := range []termbox.Event{
+		KeyEvent(0, 'a'), KeyEvent(termbox.KeyEnter, 0), KeyEvent(termbox.KeyCtrlJ, 0),
+		KeyEvent(0, 'b'), KeyEvent(termbox.KeyCtrlJ, 0), KeyEvent(termbox.KeyTab, 0), KeyEvent(0, 'c'),
+	} {
+		area.HandleKey(key.Key, key.Ch, ev)
+	}
+	if len(area.Value) != 0 {
+		t.Errorf("text was inserted before the paste ended: %q", string(area.Value))
+	}
+	area.HandleKey(KeyPasteEnd, 0, ev)
+
+	select {
+	case <-ev:
+		t.Error("the paste was submitted")
+	default:
+	}
+	if want := "a\nb\n    c"; string(area.Value) != want || area.CursorIndex != len(area.Value) {
+		t.Errorf("pasted %q with the cursor at %d, want %q", string(area.Value), area.CursorIndex, want)
+	}
+}
@@ -0,0 +1,213 @@
+package termboxUI
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/nsf/termbox-go"
+)
+
+//======================================================//
+// Text Box Search
+//======================================================//
+
+// Searching a text box works like it does in 'less': '/' opens a prompt at the bottom of the text box and matches are
+// highlighted as the search is typed. 'Enter' closes the prompt, 'n' and 'N' move to the next and previous match,
+// and 'Esc' ends the search. The bottom line shows the search along with the number of the current match.
+
+// The state of a search in a text box.
+type textSearch struct {
+	prompting bool
+	query     []rune
+	invalid   bool // the query is not a valid regular expression
+	matches   []textMatch
+	current   int
+	stale     bool // the text has changed since the matches were found
+}
+
+// A match within a displayed line, as byte offsets into the text of the line.
+type textMatch struct {
+	row        int
+	start, end int
+}
+
+// Whether the search line is shown at the bottom of the text box.
+func (tb *TextBox) searchActive() bool {
+	return tb.search.prompting || len(tb.search.query) > 0
+}
+
+// While the search prompt is open, all keys go to the text box. While a search is shown, 'Esc' ends it.
+func (tb *TextBox) ClaimsKey(key termbox.Key, ch rune) bool {
+	return tb.search.prompting || key == termbox.KeyEsc && tb.searchActive()
+}
+
+// Handle the keys for searching. The return value is 'false' if the key has nothing to do with searching.
+func (tb *TextBox) handleSearchKey(key termbox.Key, ch rune) bool {
+	if tb.search.prompting {
+		switch key {
+		case termbox.KeyEnter:
+			tb.search.prompting = false
+		case termbox.KeyEsc:
+			tb.search = textSearch{}
+		case termbox.KeyBackspace, termbox.KeyBackspace2:
+			if len(tb.search.query) > 0 {
+				tb.search.query = tb.search.query[:len(tb.search.query)-1]
+				tb.findMatches(true)
+			}
+		case termbox.KeySpace:
+			tb.search.query = append(tb.search.query, ' ')
+			tb.findMatches(true)
+		default:
+			if ch != 0 {
+				tb.search.query = append(tb.search.query, ch)
+				tb.findMatches(true)
+			}
+		}
+		// Nothing else is done with keys while the prompt is open.
+		return true
+	}
+
+	switch {
+	case key == termbox.KeyEsc && tb.searchActive():
+		tb.search = textSearch{}
+	case key == 0 && ch == '/':
+		tb.search = textSearch{prompting: true}
+	case key == 0 && ch == 'n' && len(tb.search.matches) > 0:
+		tb.moveToMatch(tb.search.current + 1)
+	case key == 0 && ch == 'N' && len(tb.search.matches) > 0:
+		tb.moveToMatch(tb.search.current - 1)
+	default:
+		return false
+	}
+	return true
+}
+
+// The regular expression for the query, built according to SearchRegexp and SearchIgnoreCase.
+func (tb *TextBox) searchPattern() (*regexp.Regexp, error) {
+	pattern := string(tb.search.query)
+	if !tb.SearchRegexp {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if tb.SearchIgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// Find all of the matches in the text.
+// When 'jump' is set, the first match from the top of the view onwards becomes the current one and is scrolled into view.
+// Otherwise the current match stays on the same line where possible.
+func (tb *TextBox) findMatches(jump bool) {
+	from := tb.activeIndex
+	if !jump && tb.search.current < len(tb.search.matches) {
+		from = tb.search.matches[tb.search.current].row
+	}
+
+	tb.search.matches = nil
+	tb.search.current = 0
+	tb.search.invalid = false
+	tb.search.stale = false
+	if len(tb.search.query) == 0 {
+		return
+	}
+
+	pattern, err := tb.searchPattern()
+	if err != nil {
+		tb.search.invalid = true
+		return
+	}
+
+	for row := 0; row < tb.text.len(); row++ {
+		for _, match := range pattern.FindAllStringIndex(spansText(tb.text.at(row)), -1) {
+			if match[0] == match[1] {
+				continue
+			}
+			tb.search.matches = append(tb.search.matches, textMatch{row: row, start: match[0], end: match[1]})
+		}
+	}
+
+	tb.search.current = sort.Search(len(tb.search.matches), func(i int) bool {
+		return tb.search.matches[i].row >= from
+	})
+	if tb.search.current == len(tb.search.matches) {
+		// Past the last match, so start again from the top.
+		tb.search.current = 0
+	}
+
+	if jump && len(tb.search.matches) > 0 {
+		tb.moveToMatch(tb.search.current)
+	}
+}
+
+// Make a match the current one and scroll it into view. The index wraps around at either end.
+func (tb *TextBox) moveToMatch(index int) {
+	count := len(tb.search.matches)
+	tb.search.current = (index%count + count) % count
+	match := tb.search.matches[tb.search.current]
+
+	if match.row < tb.activeIndex || match.row >= tb.activeIndex+tb.textHeight() {
+		tb.scrollTo(match.row)
+	}
+
+	if !tb.WrapText {
+		text := spansText(tb.text.at(match.row))
+		start, end := StringWidth(text[:match.start]), StringWidth(text[:match.end])
+		if start < tb.scrollX || end > tb.scrollX+tb.textWidth() {
+			tb.scrollX = start
+			if tb.scrollX > tb.maxScrollX() {
+				tb.scrollX = tb.maxScrollX()
+			}
+		}
+	}
+}
+
+// Highlight the matches in a displayed line. The current match is drawn underlined as well.
+func (tb *TextBox) highlightMatches(row int, line []Span) []Span {
+	matches := tb.search.matches
+	first := sort.Search(len(matches), func(i int) bool { return matches[i].row >= row })
+	if first == len(matches) || matches[first].row != row {
+		return line
+	}
+
+	var highlighted []Span
+	offset := 0
+	for i := first; i < len(matches) && matches[i].row == row; i++ {
+		match := matches[i]
+		highlighted = append(highlighted, sliceSpans(line, offset, match.start)...)
+		for _, span := range sliceSpans(line, match.start, match.end) {
+			span.Reverse = true
+			span.Underline = span.Underline || i == tb.search.current
+			highlighted = append(highlighted, span)
+		}
+		offset = match.end
+	}
+	return append(highlighted, sliceSpans(line, offset, len(spansText(line)))...)
+}
+
+// Draw the search line with its match counter at x, y.
+func (tb *TextBox) drawSearchLine(x, y, width int) {
+	FillArea(x, y, width, 1, tb.Default_fg, tb.Default_bg)
+
+	prompt := "/" + string(tb.search.query)
+	drawSpansClipped(x, y, width, 0, []Span{{Text: prompt}}, tb.Default_fg, tb.Default_bg)
+	if tb.search.prompting {
+		activeScreen.SetCursor(x+StringWidth(prompt), y)
+	}
+
+	var counter string
+	switch {
+	case tb.search.invalid:
+		counter = "bad pattern"
+	case len(tb.search.query) == 0:
+		return
+	case len(tb.search.matches) == 0:
+		counter = "no matches"
+	default:
+		counter = fmt.Sprintf("%d/%d", tb.search.current+1, len(tb.search.matches))
+	}
+	counterX := width - StringWidth(counter)
+	if counterX > StringWidth(prompt) {
+		DrawText(x+counterX, y, counter, tb.Default_fg|termbox.AttrReverse, tb.Default_bg)
+	}
+}
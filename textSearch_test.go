@@ -0,0 +1,84 @@
+package termboxUI
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Type keys into a text box, with spaces as the Space key like termbox sends them.
+func typeInto(box *TextBox, ev chan UIEvent, text string) {
+	for _, key := range TextEvents(text) {
+		box.HandleKey(key.Key, key.Ch, ev)
+	}
+}
+
+func TestTextBoxSearch(t *testing.T) {
+	screen := NewSimulationScreen(20, 4)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	box := scrollingTextBox(20, 4, false)
+	for i := 0; i < 10; i++ {
+		box.AddText("line")
+	}
+	box.AddText("a match")
+	box.AddText("two Match match")
+	ev := make(chan UIEvent, 1)
+
+	typeInto(box, ev, "/match")
+	if !box.ClaimsKey(termbox.KeyTab, 0) {
+		t.Error("keys are not claimed while the prompt is open")
+	}
+	box.HandleKey(termbox.KeyEnter, 0, ev)
+	box.Draw(0, 0)
+	if got := trimmedLine(screen, 3); got != "/match           1/2" {
+		t.Errorf("search line is %q", got)
+	}
+	if box.activeIndex+3 <= 10 {
+		t.Errorf("the first match was not scrolled into view, the view starts at %d", box.activeIndex)
+	}
+
+	typeInto(box, ev, "nn")
+	if box.search.current != 0 {
+		t.Errorf("'n' past the last match went to %d, want it to wrap around", box.search.current)
+	}
+	typeInto(box, ev, "N")
+	if box.search.current != 1 {
+		t.Errorf("'N' went to %d", box.search.current)
+	}
+
+	if !box.ClaimsKey(termbox.KeyEsc, 0) {
+		t.Error("Esc is not claimed to end the search")
+	}
+	box.HandleKey(termbox.KeyEsc, 0, ev)
+	box.Draw(0, 0)
+	if box.searchActive() || strings.Contains(screen.String(), "/match") {
+		t.Error("Esc did not end the search")
+	}
+}
+
+func TestTextBoxSearchOptions(t *testing.T) {
+	box := scrollingTextBox(20, 4, false)
+	box.AddText("Go go GO")
+	ev := make(chan UIEvent, 1)
+
+	box.SearchIgnoreCase = true
+	typeInto(box, ev, "/go")
+	if len(box.search.matches) != 3 {
+		t.Errorf("ignoring case found %d matches", len(box.search.matches))
+	}
+
+	box.HandleKey(termbox.KeyEsc, 0, ev)
+	box.SearchIgnoreCase = false
+	box.SearchRegexp = true
+	typeInto(box, ev, "/[Gg]o")
+	if len(box.search.matches) != 2 {
+		t.Errorf("the regular expression found %d matches", len(box.search.matches))
+	}
+	typeInto(box, ev, "(")
+	if !box.search.invalid {
+		t.Error("an unfinished regular expression is not reported")
+	}
+}
@@ -0,0 +1,175 @@
+package termboxUI
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//======================================================//
+// Text Box Streams
+//======================================================//
+
+// A text stream reads the lines of a reader given to TextBox.AddTextFrom on a goroutine of its own.
+// The lines wait in the stream until the text box takes them when it is drawn, so the text box itself is only ever
+// changed on the goroutine that draws it. Each new line asks the UI the stream belongs to for a redraw.
+//
+// Each UI has its own streams, one for each reader. When StartUI rebuilds the UI, the new UI takes over the streams,
+// and a new text box given the same reader takes over its stream from the old one, so the lines read from then on
+// go to the new text box. The streams of a UI stop when it stops, and a reader that is an io.Closer is closed then,
+// which releases a read that is waiting.
+type textStream struct {
+	source io.Reader
+	owner  *streamRegistry
+
+	mutex      sync.Mutex
+	box        *TextBox // the text box the lines go to
+	customType uint16   // the custom type of the text box, for the error event of a failed read
+	pending    []string // lines read but not yet taken by the text box
+	stopped    bool
+}
+
+// The streams of a UI that are reading, by their reader.
+type streamRegistry struct {
+	ui *UI
+
+	mutex    sync.Mutex
+	bySource map[io.Reader]*textStream
+}
+
+// Get the UI's streams, creating them the first time they are needed.
+func (ui *UI) textStreams() *streamRegistry {
+	if ui.streams == nil {
+		ui.streams = &streamRegistry{ui: ui, bySource: make(map[io.Reader]*textStream)}
+	}
+	return ui.streams
+}
+
+// Get the stream for a reader, starting it if it isn't running yet, and make the text box the one it adds lines to.
+func (r *streamRegistry) start(box *TextBox, source io.Reader) *textStream {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// A reader that can't be a map key, such as a struct holding a slice, can't be shared either.
+	comparable := reflect.TypeOf(source).Comparable()
+
+	stream := r.bySource[source]
+	if !comparable || stream == nil {
+		stream = &textStream{source: source, owner: r}
+		if comparable {
+			r.bySource[source] = stream
+		}
+		go stream.read()
+	}
+
+	stream.mutex.Lock()
+	stream.box = box
+	stream.customType = box.CustomType
+	stream.mutex.Unlock()
+	return stream
+}
+
+// Stop every stream, for when the UI stops.
+func (r *streamRegistry) stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for source, stream := range r.bySource {
+		stream.mutex.Lock()
+		stream.stopped = true
+		stream.mutex.Unlock()
+		delete(r.bySource, source)
+
+		if closer, ok := source.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+func (s *textStream) read() {
+	defer s.forget()
+
+	reader := bufio.NewReader(s.source)
+	for {
+		line, err := reader.ReadString('\n')
+		if !s.push(strings.TrimSuffix(line, "\n"), len(line) > 0) {
+			return
+		}
+		if err != nil {
+			if err != io.EOF {
+				s.fail(err)
+			}
+			return
+		}
+	}
+}
+
+// Add a line for the text box to take. The return value is 'false' once the stream has stopped.
+func (s *textStream) push(line string, ok bool) bool {
+	s.mutex.Lock()
+	stopped := s.stopped
+	if ok && !stopped {
+		s.pending = append(s.pending, line)
+	}
+	s.mutex.Unlock()
+
+	if ok && !stopped {
+		s.owner.ui.RequestRedraw()
+	}
+	return !stopped
+}
+
+// Report a failed read to the UI, unless the read failed because the stream was stopped.
+func (s *textStream) fail(err error) {
+	s.mutex.Lock()
+	customType, stopped := s.customType, s.stopped
+	s.mutex.Unlock()
+
+	if !stopped {
+		s.owner.ui.Send(NewErrorEvent(customType, err))
+	}
+}
+
+// Take the stream out of the registry once its reader is done, so that nothing takes it over.
+func (s *textStream) forget() {
+	s.owner.mutex.Lock()
+	defer s.owner.mutex.Unlock()
+
+	if s.owner.bySource[s.source] == s {
+		delete(s.owner.bySource, s.source)
+	}
+}
+
+// Take the lines that have been read, if the stream still belongs to the text box.
+func (s *textStream) take(box *TextBox) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.box != box {
+		return nil
+	}
+	lines := s.pending
+	s.pending = nil
+	return lines
+}
+
+// Add the lines read by the text box's stream, starting the stream if AddTextFrom was called since the last draw.
+// The stream belongs to the UI the text box is drawn for. Nothing is read while the text box is drawn outside of a UI.
+func (tb *TextBox) takeStreamedText() {
+	if tb.reader != nil {
+		ui := screenUI()
+		if ui == nil {
+			return
+		}
+		tb.stream = ui.textStreams().start(tb, tb.reader)
+		tb.reader = nil
+	}
+	if tb.stream == nil {
+		return
+	}
+	for _, line := range tb.stream.take(tb) {
+		tb.AddStyledText(tb.ANSI.Parse(line)...)
+	}
+}
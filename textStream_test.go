@@ -0,0 +1,159 @@
+package termboxUI
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+// A field that draws a text box of its own, the way a composite field would, without adding it to the UI.
+type framedText struct {
+	box *TextBox
+}
+
+func (f *framedText) Draw(x, y int) { f.box.Draw(x+1, y+1) }
+
+func (f *framedText) HandleKey(key termbox.Key, ch rune, event chan UIEvent) bool { return false }
+
+// Wait until the screen shows the text, or fail after a second.
+func waitForText(t *testing.T, screen *SimulationScreen, text string) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if strings.Contains(screen.String(), text) {
+			return
+		}
+	}
+	t.Fatalf("the screen never showed %q:\n%s", text, screen.String())
+}
+
+func TestTextBoxKeepsEmptyLines(t *testing.T) {
+	box := CreateTextBox(10, 5, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	box.AddText("a\n\nb")
+	if box.text.len() != 3 || spansText(box.text.at(1)) != "" || spansText(box.text.at(2)) != "b" {
+		t.Fatalf("got %d lines", box.text.len())
+	}
+}
+
+func TestTextBoxStreamsWhereverItIsDrawn(t *testing.T) {
+	screen := NewSimulationScreen(30, 6)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	source, input := io.Pipe()
+	box := CreateTextBox(20, 4, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	box.AddTextFrom(source)
+
+	ui := new(UI)
+	ui.AddField(&framedText{box}, 0, 0, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ui.Run(ctx) }()
+
+	io.WriteString(input, "first\n\x1b[31msecond\x1b[0m\n")
+	waitForText(t, screen, "second")
+	if line := screen.Line(2); !strings.HasPrefix(line, " second") || screen.Cell(1, 2).Fg != termbox.ColorRed {
+		t.Errorf("second line is %q", line)
+	}
+
+	cancel()
+	<-done
+
+	// The stream stops with the UI, and the reader is closed to release the read that was waiting.
+	if _, err := io.WriteString(input, "dropped\n"); err != io.ErrClosedPipe {
+		t.Errorf("writing after the UI stopped gave %v, want the reader to be closed", err)
+	}
+}
+
+func TestStoppingAUIOnlyStopsItsOwnStreams(t *testing.T) {
+	var screens []*SimulationScreen
+	var inputs []*io.PipeWriter
+	var stops []func()
+	for i := 0; i < 2; i++ {
+		source, input := io.Pipe()
+		box := CreateTextBox(20, 4, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+		box.AddTextFrom(source)
+
+		screen := NewSimulationScreen(30, 6)
+		ui := &UI{Screen: screen}
+		ui.AddField(box, 0, 0, false)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- ui.Run(ctx) }()
+
+		screens = append(screens, screen)
+		inputs = append(inputs, input)
+		stops = append(stops, func() {
+			cancel()
+			<-done
+		})
+	}
+	defer stops[1]()
+
+	io.WriteString(inputs[0], "first\n")
+	waitForText(t, screens[0], "first")
+	stops[0]()
+
+	io.WriteString(inputs[1], "second\n")
+	waitForText(t, screens[1], "second")
+}
+
+func TestTextBoxTakesOverAStream(t *testing.T) {
+	source, input := io.Pipe()
+	ui := new(UI)
+	defer ui.textStreams().stop()
+
+	older := CreateTextBox(20, 4, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	older.AddTextFrom(source)
+	ui.useScreen(older.takeStreamedText)
+
+	newer := CreateTextBox(20, 4, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	newer.AddTextFrom(source)
+	ui.useScreen(newer.takeStreamedText)
+	if older.stream != newer.stream {
+		t.Fatal("the reader is read by a second stream")
+	}
+
+	io.WriteString(input, "line\n")
+	for deadline := time.Now().Add(time.Second); newer.text.len() == 0 && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		ui.useScreen(newer.takeStreamedText)
+	}
+	ui.useScreen(older.takeStreamedText)
+	if newer.text.len() != 1 || spansText(newer.text.at(0)) != "line" {
+		t.Errorf("the new text box has %d lines", newer.text.len())
+	}
+	if older.text.len() != 0 {
+		t.Errorf("the old text box still gets lines")
+	}
+}
+
+func TestTextBoxFollowsNewLines(t *testing.T) {
+	box := CreateTextBox(10, 3, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	box.Follow = true
+	box.MaxLines = 5
+	for i := 0; i < 8; i++ {
+		box.AddText(fmt.Sprint(i))
+	}
+	if box.text.len() != 5 || box.activeIndex != box.maxScroll() {
+		t.Fatalf("kept %d lines and shows from %d, want the last 3 of 5", box.text.len(), box.activeIndex)
+	}
+
+	// Scrolling up stops the text box from following until it is back at the bottom.
+	ev := make(chan UIEvent, 1)
+	box.HandleKey(termbox.KeyArrowUp, 0, ev)
+	top := box.activeIndex
+	box.MaxLines = 0
+	box.AddText("more")
+	if box.activeIndex != top {
+		t.Errorf("the view moved from %d to %d while scrolled up", top, box.activeIndex)
+	}
+	box.HandleKey(termbox.KeyEnd, 0, ev)
+	box.AddText("again")
+	if box.activeIndex != box.maxScroll() {
+		t.Errorf("the view stopped following after going back to the bottom")
+	}
+}
@@ -0,0 +1,103 @@
+package termboxUI
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func scrollingTextBox(width, height int, wrap bool) *TextBox {
+	return CreateTextBox(width, height, false, wrap, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+}
+
+func TestTextBoxScrollsSideways(t *testing.T) {
+	screen := NewSimulationScreen(10, 2)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	box := scrollingTextBox(5, 2, false)
+	box.AddText("abcdefgh")
+	box.AddText("xy")
+	ev := make(chan UIEvent, 1)
+	for i := 0; i < 5; i++ {
+		box.HandleKey(termbox.KeyArrowRight, 0, ev)
+	}
+	if box.scrollX != 3 {
+		t.Fatalf("scrolled to %d, want the end of the longest line at 3", box.scrollX)
+	}
+	box.Draw(0, 0)
+	if got := strings.TrimRight(screen.Line(0), " "); got != "defgh" {
+		t.Errorf("first line shows %q", got)
+	}
+
+	box.HandleKey(termbox.KeyHome, 0, ev)
+	if box.scrollX != 0 || box.activeIndex != 0 {
+		t.Errorf("Home left the scroll at %d, %d", box.scrollX, box.activeIndex)
+	}
+}
+
+func TestTextBoxPages(t *testing.T) {
+	box := scrollingTextBox(10, 4, false)
+	for i := 0; i < 20; i++ {
+		box.AddText(fmt.Sprint("line ", i))
+	}
+	ev := make(chan UIEvent, 1)
+
+	box.HandleKey(termbox.KeyPgdn, 0, ev)
+	if box.activeIndex != 3 {
+		t.Errorf("PageDown scrolled to %d, want 3", box.activeIndex)
+	}
+	box.HandleKey(termbox.KeyEnd, 0, ev)
+	if box.activeIndex != 16 {
+		t.Errorf("End scrolled to %d, want 16", box.activeIndex)
+	}
+	box.HandleKey(termbox.KeyPgup, 0, ev)
+	if box.activeIndex != 13 {
+		t.Errorf("PageUp scrolled to %d, want 13", box.activeIndex)
+	}
+}
+
+func TestTextBoxLongestLineFollowsEviction(t *testing.T) {
+	box := scrollingTextBox(5, 2, false)
+	box.MaxLines = 2
+	box.AddText("a very long line")
+	box.AddText("short")
+	if box.maxScrollX() != 11 {
+		t.Fatalf("maxScrollX = %d, want 11", box.maxScrollX())
+	}
+
+	box.AddText("medium line")
+	if box.maxScrollX() != 6 {
+		t.Errorf("maxScrollX = %d after the longest line was dropped, want 6", box.maxScrollX())
+	}
+
+	box.WrapText = true
+	box.Draw(0, 0)
+	if box.maxScrollX() != 0 {
+		t.Errorf("maxScrollX = %d after wrapping, want 0", box.maxScrollX())
+	}
+}
+
+func TestTextBoxWrapsAtWords(t *testing.T) {
+	screen := NewSimulationScreen(12, 4)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	box := scrollingTextBox(10, 4, true)
+	box.AddText("the quick brown fox")
+	box.Draw(0, 0)
+	for y, want := range []string{"the quick", "brown fox"} {
+		if got := trimmedLine(screen, y); got != want {
+			t.Errorf("row %d is %q, want %q", y, got, want)
+		}
+	}
+
+	// A wider text box wraps the text it already has again.
+	box.Width = 20
+	box.AddText("jumps")
+	if got := spansText(box.text.at(0)); got != "the quick brown fox" || box.text.len() != 2 {
+		t.Errorf("after widening, the first line is %q out of %d", got, box.text.len())
+	}
+}
@@ -4,6 +4,8 @@ package termboxUI
 
 import (
 	"bytes"
+	"context"
+	"sync"
 
 	"github.com/nsf/termbox-go"
 )
@@ -65,26 +67,67 @@ type DrawHandler interface {
 	HandleKey(key termbox.Key, ch rune, event chan UIEvent) bool
 }
 
+// OverlayDrawer is implemented by fields that at times draw outside of their own area, such as a list of suggestions.
+// A UI calls DrawOverlay with the location of the field once every field has been drawn, so the other fields don't cover it.
+type OverlayDrawer interface {
+	DrawOverlay(x, y int)
+}
+
 //==========================//
 //            UI            //
 //==========================//
 
 // All ui fields should adhere to this interface.
+// TabIndex orders the field for Tab and Shift+Tab focus changes. See UI.SetTabIndex.
+// KeyMap holds key bindings that only apply while the field has focus. See UI.BindField.
+// Width and Height are recorded each time the field is drawn if the element implements Sizer, and are used to find the field under the mouse.
 type Field struct {
 	X        int
 	Y        int
+	Width    int
+	Height   int
 	Element  DrawHandler
 	HasFocus bool
+	TabIndex int
+	KeyMap   map[KeyBinding]Action
 }
 
 // This is the definition of all of the fields in the current termbox GUI.
+// OnFocus and OnBlur are optional callbacks for when a field gains or loses focus.
+// OnResize is an optional callback with the screen dimensions, called when a UI run with Start begins and after every resize.
+// KeyMap holds the global key bindings. When it is nil the bindings from DefaultKeyMap are used.
+// MouseEnabled turns on mouse reporting while the UI runs. See EnableMouse.
+// Screen is the backend the UI draws to and reads input from. When it is nil the default set by SetScreen is used.
+// Events and CustomEvents hold the event handlers by result type and custom type. See DispatchEvent for the whole event pipeline,
+// which also includes the Middleware and the OnUnhandled hook. ErrorMode decides what happens to errors from that pipeline.
 type UI struct {
 	Fg           termbox.Attribute
 	Bg           termbox.Attribute
 	Events       map[ResultType]func(UIEvent)
 	CustomEvents map[uint16]func(UIEvent)
+	Middleware   []EventMiddleware
+	OnUnhandled  func(UIEvent)
+	ErrorMode    ErrorMode
+	OnFocus      func(field *Field)
+	OnBlur       func(field *Field)
+	OnResize     func(width, height int)
+	KeyMap       map[KeyBinding]Action
+	MouseEnabled bool
+	Screen       Screen
+
+	typeHandlers   map[ResultType]EventDispatcher
+	customHandlers map[uint16]EventDispatcher
+	errorPopup     *Popup
+	streams        *streamRegistry
 
-	fields []Field
+	fields   []Field
+	invalid  bool
+	quitting bool
+	results  chan UIEvent
+	input    chan termbox.Event
+
+	mailboxMutex sync.Mutex
+	mailbox      *mailbox
 }
 
 // AddField adds a new ui field to the defined UI
@@ -92,31 +135,111 @@ type UI struct {
 // hasFocus will give the input handling priority to the new field.
 func (ui *UI) AddField(element DrawHandler, x, y int, hasFocus bool) { //TODO: AddStaticField and AddInteractiveField
 	var newFields = make([]Field, len(ui.fields)+1)
-	var field = Field{x, y, element, hasFocus}
+	var field = Field{X: x, Y: y, Element: element, HasFocus: hasFocus}
 	copy(newFields[:], ui.fields[:])
 	newFields[len(ui.fields)] = field
 	ui.fields = newFields
+
+	if handler, ok := element.(FocusHandler); ok && hasFocus {
+		handler.HandleFocus(true)
+	}
+	if attacher, ok := element.(Attacher); ok {
+		attacher.Attach(ui)
+	}
+	ui.Invalidate()
 	return
 }
 
-// Draw clears the terminal and then calls the Draw method for all of its fields at their set locations.
+// RemoveField takes the field holding the given element out of the UI.
+// The return value is 'false' if the element is not part of the UI.
+func (ui *UI) RemoveField(element DrawHandler) bool {
+	for i, field := range ui.fields {
+		if field.Element == element {
+			ui.fields = append(ui.fields[:i:i], ui.fields[i+1:]...)
+			ui.Invalidate()
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceField swaps the element of a field for a new one, keeping the position, focus and tab index of the field.
+// The return value is 'false' if the old element is not part of the UI.
+func (ui *UI) ReplaceField(oldElement, newElement DrawHandler) bool {
+	for i := range ui.fields {
+		field := &ui.fields[i]
+		if field.Element != oldElement {
+			continue
+		}
+		field.Element = newElement
+		if handler, ok := newElement.(FocusHandler); ok && field.HasFocus {
+			handler.HandleFocus(true)
+		}
+		if attacher, ok := newElement.(Attacher); ok {
+			attacher.Attach(ui)
+		}
+		ui.Invalidate()
+		return true
+	}
+	return false
+}
+
+// MoveField changes the location where the field holding the given element is drawn.
+// The return value is 'false' if the element is not part of the UI.
+func (ui *UI) MoveField(element DrawHandler, x, y int) bool {
+	for i := range ui.fields {
+		if ui.fields[i].Element == element {
+			ui.fields[i].X, ui.fields[i].Y = x, y
+			ui.Invalidate()
+			return true
+		}
+	}
+	return false
+}
+
+// Invalidate marks the UI as needing to be drawn again.
+// When the UI is run with Start, the screen is only redrawn after the UI has been invalidated.
+// Input consumed by a field, UI events and resizes invalidate the UI automatically, so this is only needed for changes made elsewhere.
+func (ui *UI) Invalidate() {
+	ui.invalid = true
+}
+
+// Draw clears the screen and then calls the Draw method for all of its fields at their set locations.
 func (ui *UI) Draw() {
-	termbox.Clear(ui.Fg, ui.Bg)
-	for _, field := range ui.fields {
+	ui.useScreen(ui.draw)
+}
+
+func (ui *UI) draw() {
+	ui.invalid = false
+
+	activeScreen.Clear(ui.Fg, ui.Bg)
+	activeScreen.HideCursor()
+	for i := range ui.fields {
+		field := &ui.fields[i]
 		field.Element.Draw(field.X, field.Y)
+		if sizer, ok := field.Element.(Sizer); ok {
+			field.Width, field.Height = sizer.Size()
+		}
+	}
+	for _, field := range ui.fields {
+		if overlay, ok := field.Element.(OverlayDrawer); ok {
+			overlay.DrawOverlay(field.X, field.Y)
+		}
 	}
-	termbox.Flush()
+	if ui.errorPopup != nil {
+		ui.errorPopup.Draw(0, 0)
+	}
+	activeScreen.Flush()
 	return
 }
 
+// PollEvent returns the channel of input events read from the screen while the UI is running.
+// All events come from a single reader goroutine, so none are lost between calls. The channel is nil while the UI is not running.
 func (ui *UI) PollEvent() chan termbox.Event {
-	event := make(chan termbox.Event)
-	go func() {
-		event <- termbox.PollEvent()
-	}()
-	return event
+	return ui.input
 }
 
+// HandleCustomEvent runs only the CustomEvents handler for the event's custom type, skipping the rest of the event pipeline.
 func (ui *UI) HandleCustomEvent(event UIEvent) {
 	if action, ok := ui.CustomEvents[event.CustomType]; ok {
 		action(event)
@@ -125,64 +248,172 @@ func (ui *UI) HandleCustomEvent(event UIEvent) {
 
 // Send the termbox key and character input to the UI's fields.
 // As soon as the event is consumed by a field, this returns. This way only one field can handle that input at a time.
+// Key bindings are checked first: those of the focused field, then the global ones in KeyMap.
+// When an error is shown in a popup, the key press only hides the popup.
 func (ui *UI) HandleInput(key termbox.Key, ch rune, event chan UIEvent) (eventConsumed bool) {
-	eventConsumed = false
+	return ui.handleKey(KeyBinding{Key: key, Ch: ch}, event)
+}
 
-inputLoop:
-	for _, field := range ui.fields {
-		if field.HasFocus {
-			eventConsumed = field.Element.HandleKey(key, ch, event)
-			break inputLoop
+// Send a key press with its modifiers through the key bindings and on to the focused field.
+// While an error popup is shown, the key press only hides it.
+func (ui *UI) handleKey(binding KeyBinding, event chan UIEvent) (eventConsumed bool) {
+	if ui.dismissError() {
+		return true
+	}
+
+	field := ui.FocusedField()
+
+	if field != nil {
+		if claimer, ok := field.Element.(KeyClaimer); ok && claimer.ClaimsKey(binding.Key, binding.Ch) {
+			return sendKey(field.Element, binding, event)
 		}
 	}
 
+	if action := ui.lookupBinding(field, binding); action != nil && action(ui) {
+		return true
+	}
+
+	if field != nil {
+		eventConsumed = sendKey(field.Element, binding, event)
+	}
 	return
 }
 
 // This gets the whole ball rolling.
-// The input function is where the ui is defined.
+// The input function is where the ui is defined. It is called again to rebuild the whole UI after every UI event and resize,
+// so any state that should survive must be kept outside of the UI. See UI.Start for a UI that is built only once.
 func StartUI(buildUserInterface func() *UI, arg ...interface{}) error {
-	if err := termbox.Init(); err != nil {
+	return runUI(context.Background(), nil, buildUserInterface)
+}
+
+// StartUIContext is StartUI with a context. The UI stops and the context's error is returned once the context is cancelled.
+func StartUIContext(ctx context.Context, buildUserInterface func() *UI) error {
+	return runUI(ctx, nil, buildUserInterface)
+}
+
+// Start runs the UI in retained mode: the UI is built once by the caller and then mutated by its fields and event handlers.
+// Unlike StartUI, the state of every field is kept between events and the screen is only redrawn once the UI has been invalidated.
+// Since the screen size is not known before the UI starts, layout that depends on it belongs in OnResize.
+func (ui *UI) Start() error {
+	return ui.Run(context.Background())
+}
+
+// Run is Start with a context. The UI stops and the context's error is returned once the context is cancelled.
+// Other goroutines can change the running UI through Post and Send, and can stop it with Post(ui.Quit).
+func (ui *UI) Run(ctx context.Context) error {
+	return runUI(ctx, ui, nil)
+}
+
+// Let the UI adjust its layout to the current screen size.
+func (ui *UI) resize() {
+	if ui.OnResize != nil {
+		ui.OnResize(ui.screen().Size())
+	}
+	ui.Invalidate()
+}
+
+// Pass a UI event reported by a field or sent from another goroutine through the event pipeline.
+// An error is returned if the UI has to stop.
+func (ui *UI) handleUIEvent(event UIEvent) error {
+	ui.Invalidate()
+	return ui.handleError(ui.DispatchEvent(event))
+}
+
+// The event loop shared by both modes. The UI is rebuilt with buildUserInterface when it is not nil.
+func runUI(ctx context.Context, ui *UI, buildUserInterface func() *UI) error {
+	rebuild := buildUserInterface != nil
+	if ui == nil {
+		ui = new(UI)
+	}
+	screen := ui.screen()
+	if err := screen.Init(); err != nil {
 		return err
 	}
-	defer termbox.Close()
+	defer screen.Close()
+	if !rebuild {
+		ui.resize()
+	}
+
+	defer func() {
+		ui.textStreams().stop()
+	}()
 
-	ui := new(UI)
+	reader := startInputReader(screen)
+	defer reader.Close()
+	defer func() {
+		ui.input = nil
+	}()
 
+	// This channel is never closed, since commands running on their own goroutines may still send to it after the UI stops.
+	// Whatever is sent to it is handled along with the rest of the mailbox.
 	inputEvent := make(chan UIEvent, 1)
-	defer close(inputEvent)
+	stopCollecting := ui.getMailbox().collect(inputEvent)
+	defer stopCollecting()
 
-	refresh := true
+	refresh := rebuild
+	ui.results = inputEvent
+	ui.input = reader.events
+	ui.quitting = false
+	ui.applyInputMode()
 
-loop:
-	for {
+	for !ui.quitting {
 		if refresh {
-			ui = buildUserInterface()
+			newUI := buildUserInterface()
+			ui.shareMailbox(newUI)
+			newUI.errorPopup = ui.errorPopup
+			newUI.streams = ui.streams
+			if newUI.Screen == nil {
+				newUI.Screen = screen
+			}
+			ui = newUI
+			ui.results = inputEvent
+			ui.input = reader.events
+			ui.applyInputMode()
 			refresh = false
 		}
-		ui.Draw()
+		if rebuild || ui.invalid {
+			ui.Draw()
+		}
 
+		var err error
 		select {
-		case event := <-inputEvent:
-			if event.Error != nil {
-				return event.Error
-			}
-			ui.HandleCustomEvent(event)
-			refresh = true
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ui.getMailbox().wake:
+			ui.useScreen(func() {
+				for _, item := range ui.getMailbox().drain() {
+					if item.event != nil {
+						if err = ui.handleUIEvent(*item.event); err != nil {
+							return
+						}
+						refresh = rebuild
+					} else if item.action != nil {
+						item.action()
+					}
+				}
+				ui.Invalidate()
+			})
 		case ev := <-ui.PollEvent():
-			switch ev.Type {
-			case termbox.EventKey:
-				switch ev.Key {
-				case termbox.KeyEsc:
-					break loop
-				case termbox.KeyCtrlC:
-					break loop
-				default:
-					ui.HandleInput(ev.Key, ev.Ch, inputEvent)
+			ui.useScreen(func() {
+				switch ev.Type {
+				case termbox.EventKey:
+					if ui.handleKey(KeyBinding{Key: ev.Key, Ch: ev.Ch, Mod: ev.Mod}, inputEvent) {
+						ui.Invalidate()
+					}
+				case termbox.EventMouse:
+					if ui.handleMouse(ev.MouseX, ev.MouseY, ev.Key, ev.Mod, inputEvent) {
+						ui.Invalidate()
+					}
+				case termbox.EventResize:
+					refresh = rebuild
+					if !rebuild {
+						ui.resize()
+					}
 				}
-			case termbox.EventResize:
-				refresh = true
-			}
+			})
+		}
+		if err != nil {
+			return err
 		}
 	}
 
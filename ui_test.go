@@ -0,0 +1,134 @@
+package termboxUI
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Run a UI on the screen until the test ends.
+func runInBackground(t *testing.T, run func(ctx context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestRetainedUIKeepsItsFields(t *testing.T) {
+	screen := NewSimulationScreen(40, 10)
+	SetScreen(screen)
+	t.Cleanup(func() { SetScreen(nil) })
+
+	ui := new(UI)
+	eb := CreateEditBox(30, "", 1, termbox.ColorDefault, termbox.ColorDefault)
+	log := CreateTextBox(30, 4, false, false, TextAlignmentLeft, TextAlignmentDefault, termbox.ColorDefault, termbox.ColorDefault)
+	ui.AddField(eb, 0, 0, true)
+	ui.AddField(log, 0, 4, false)
+	ui.CustomEvents = map[uint16]func(UIEvent){1: func(event UIEvent) {
+		text, _ := event.StringResult()
+		log.AddText("got " + text)
+	}}
+	runInBackground(t, ui.Run)
+
+	screen.InjectKeys("one\n")
+	waitForText(t, screen, "got one")
+	screen.InjectKeys("tw")
+	waitForText(t, screen, "/> tw")
+	screen.InjectKeys("o\n")
+	waitForText(t, screen, "got two")
+
+	if !strings.Contains(screen.String(), "got one") {
+		t.Error("the text box lost its first line")
+	}
+}
+
+func TestStartUIRebuildsAfterEvents(t *testing.T) {
+	screen := NewSimulationScreen(40, 10)
+	SetScreen(screen)
+	t.Cleanup(func() { SetScreen(nil) })
+
+	builds := make(chan int, 10)
+	count := 0
+	runInBackground(t, func(ctx context.Context) error {
+		return StartUIContext(ctx, func() *UI {
+			count++
+			builds <- count
+			ui := new(UI)
+			ui.AddField(CreateButton(10, 3, "ok", termbox.ColorDefault, termbox.ColorDefault), 0, 0, true)
+			return ui
+		})
+	})
+
+	<-builds
+	screen.InjectKey(termbox.KeyEnter, 0)
+	if <-builds != 2 {
+		t.Error("the UI was not built again after the button was pressed")
+	}
+}
+
+// A screen that counts the frames drawn to it.
+type countingScreen struct {
+	*SimulationScreen
+	flushes int32
+}
+
+func (s *countingScreen) Flush() error {
+	atomic.AddInt32(&s.flushes, 1)
+	return s.SimulationScreen.Flush()
+}
+
+func TestUIsRunOnTheirOwnScreens(t *testing.T) {
+	fallback := NewSimulationScreen(40, 5)
+	SetScreen(fallback)
+	t.Cleanup(func() { SetScreen(nil) })
+
+	var screens []*countingScreen
+	for _, text := range []string{"one", "two"} {
+		screen := &countingScreen{SimulationScreen: NewSimulationScreen(40, 5)}
+		ui := &UI{Screen: screen}
+		ui.AddField(CreateEditBox(30, "", 0, termbox.ColorDefault, termbox.ColorDefault), 0, 0, true)
+		runInBackground(t, ui.Run)
+		screen.InjectKeys(text)
+		screens = append(screens, screen)
+	}
+
+	waitForText(t, screens[0].SimulationScreen, "one")
+	waitForText(t, screens[1].SimulationScreen, "two")
+	for i, screen := range screens {
+		if atomic.LoadInt32(&screen.flushes) == 0 {
+			t.Errorf("screen %d was never flushed", i)
+		}
+	}
+	if strings.Contains(screens[0].String(), "two") || strings.Contains(screens[1].String(), "one") {
+		t.Errorf("the UIs drew to each other's screens:\n%s\n%s", screens[0].String(), screens[1].String())
+	}
+	if text := strings.TrimSpace(fallback.String()); text != "" {
+		t.Errorf("the default screen shows %q", text)
+	}
+}
+
+func TestTypingAheadOfSubmittedText(t *testing.T) {
+	ui := new(UI)
+	ui.AddField(CreateEditBox(20, "", 1, termbox.ColorDefault, termbox.ColorDefault), 0, 0, true)
+	var submitted []string
+	ui.CustomEvents = map[uint16]func(UIEvent){1: func(event UIEvent) {
+		text, _ := event.StringResult()
+		if submitted = append(submitted, text); len(submitted) == 20 {
+			ui.Quit()
+		}
+	}}
+
+	// The edit box reports each line from the event loop, while the loop still has the following keys to read.
+	screen := NewSimulationScreen(40, 10)
+	screen.InjectKeys("a" + strings.Repeat("\n", 20))
+	runUntilQuit(t, ui, screen)
+	if len(submitted) != 20 || submitted[0] != "a" {
+		t.Errorf("submitted %q", submitted)
+	}
+}
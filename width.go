@@ -0,0 +1,248 @@
+package termboxUI
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+//======================================================//
+// Display Width
+//======================================================//
+
+// Text is laid out by grapheme cluster: a base character along with any combining marks, variation selectors,
+// emoji modifiers and zero width joiner sequences that follow it. Each cluster takes up the display width of its base
+// character, which is two columns for East Asian wide characters and most emoji.
+// Clusters are found with the Unicode segmentation rules of the uniseg package.
+
+// StringWidth returns the number of terminal columns needed to display the text.
+func StringWidth(text string) (width int) {
+	for len(text) > 0 {
+		_, clusterWidth, size := nextGrapheme(text)
+		width += clusterWidth
+		text = text[size:]
+	}
+	return
+}
+
+// RunesWidth returns the number of terminal columns needed to display the runes.
+func RunesWidth(text []rune) int {
+	return StringWidth(string(text))
+}
+
+// TruncateString cuts the text down to at most the given number of columns, never splitting a cluster.
+func TruncateString(text string, width int) string {
+	head, _ := splitAtWidth(text, width)
+	return head
+}
+
+// Split the text into the part that fits within the given number of columns and the rest.
+// At least one cluster is always placed in the head so that a narrow width cannot stall wrapping.
+func splitAtWidth(text string, width int) (head, tail string) {
+	used, offset := 0, 0
+	for offset < len(text) {
+		_, clusterWidth, size := nextGrapheme(text[offset:])
+		if used+clusterWidth > width && offset > 0 {
+			break
+		}
+		used += clusterWidth
+		offset += size
+	}
+	return text[:offset], text[offset:]
+}
+
+// Find the grapheme cluster at the start of the text.
+// The rune that stands for the cluster on screen, its display width and its length in bytes are returned.
+func nextGrapheme(text string) (ch rune, width, size int) {
+	if text == "" {
+		return utf8.RuneError, 0, 0
+	}
+
+	// Only the start of the text is segmented, so that going through a long text is not quadratic.
+	// A cluster that reaches the end of that part may go on past it, so the part is made longer until it doesn't.
+	var runes []rune
+	for end := 64; ; end *= 2 {
+		if end >= len(text) {
+			end = len(text)
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+		graphemes := uniseg.NewGraphemes(text[:end])
+		graphemes.Next()
+		runes = graphemes.Runes()
+		if _, size = graphemes.Positions(); size < end || end == len(text) {
+			break
+		}
+	}
+	cluster := text[:size]
+
+	width = runewidth.RuneWidth(runes[0])
+	switch {
+	case isRegionalIndicator(runes[0]) && len(runes) > 1:
+		// A pair of regional indicators makes up a single flag.
+		width = 2
+	case strings.ContainsRune(cluster, '\ufe0f'):
+		// Emoji presentation selector.
+		width = 2
+	}
+	return clusterRune(cluster, runes[0]), width, size
+}
+
+// A terminal cell holds a single rune, so a cluster is drawn as the character it composes to, such as 'é' for an 'e'
+// followed by a combining acute accent. Clusters that don't compose to a single character are drawn as their base rune.
+func clusterRune(cluster string, base rune) rune {
+	if len(cluster) == utf8.RuneLen(base) {
+		return base
+	}
+	if composed := norm.NFC.String(cluster); utf8.RuneCountInString(composed) == 1 {
+		ch, _ := utf8.DecodeRuneInString(composed)
+		return ch
+	}
+	return base
+}
+
+func isRegionalIndicator(ch rune) bool {
+	return ch >= 0x1f1e6 && ch <= 0x1f1ff
+}
+
+//======================================================//
+// Wrapping
+//======================================================//
+
+// WrapString breaks a line of text into lines that are at most 'width' columns wide.
+// Lines are broken at whitespace, which is dropped at the break. A word that is wider than a whole line is split,
+// with a hyphen when the split falls between two letters. Continuation lines keep the indentation of the first line,
+// unless it takes up more than half of the width.
+func WrapString(text string, width int) []string {
+	var lines []string
+	for _, wrapped := range wrapText(text, width) {
+		var line strings.Builder
+		for _, r := range wrapped.ranges {
+			line.WriteString(text[r[0]:r[1]])
+		}
+		if wrapped.hyphen {
+			line.WriteByte('-')
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// A line produced by wrapping, given as byte ranges of the original text so that styled text can be wrapped the same way.
+type wrappedLine struct {
+	ranges [][2]int
+	hyphen bool // a hyphen follows the text
+}
+
+func (wl *wrappedLine) add(start, end int) {
+	if start == end {
+		return
+	}
+	if n := len(wl.ranges); n > 0 && wl.ranges[n-1][1] == start {
+		wl.ranges[n-1][1] = end
+		return
+	}
+	wl.ranges = append(wl.ranges, [2]int{start, end})
+}
+
+// The wrapping engine behind WrapString.
+func wrapText(text string, width int) []wrappedLine {
+	if width <= 0 || StringWidth(text) <= width {
+		return []wrappedLine{{ranges: [][2]int{{0, len(text)}}}}
+	}
+
+	indent := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+	indentWidth := StringWidth(text[:indent])
+
+	var lines []wrappedLine
+	var line wrappedLine
+	lineWidth := 0
+	if indentWidth < width {
+		line.add(0, indent)
+		lineWidth = indentWidth
+	}
+
+	continuation := indent
+	if indentWidth*2 > width {
+		continuation = 0
+	}
+	newLine := func() {
+		lines = append(lines, line)
+		line = wrappedLine{}
+		line.add(0, continuation)
+		lineWidth = StringWidth(text[:continuation])
+	}
+
+	empty := true
+	for offset := indent; offset < len(text); {
+		start := len(text) - len(strings.TrimLeftFunc(text[offset:], unicode.IsSpace))
+		end := strings.IndexFunc(text[start:], unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		if start == end {
+			break
+		}
+
+		gapWidth := StringWidth(text[offset:start])
+		wordWidth := StringWidth(text[start:end])
+		if !empty && lineWidth+gapWidth+wordWidth <= width {
+			line.add(offset, end)
+			lineWidth += gapWidth + wordWidth
+			offset = end
+			continue
+		}
+		offset = end
+
+		if !empty {
+			newLine()
+		}
+
+		// The word doesn't fit even on a line of its own, so it is split.
+		for lineWidth+wordWidth > width {
+			size, hyphen := breakWord(text[start:end], width-lineWidth)
+			line.add(start, start+size)
+			line.hyphen = hyphen
+			start += size
+			newLine()
+			wordWidth = StringWidth(text[start:end])
+		}
+
+		line.add(start, end)
+		lineWidth += wordWidth
+		empty = false
+	}
+
+	return append(lines, line)
+}
+
+// Find where to split a word so that the head fits within the given number of columns. The size of the head in bytes is returned.
+// A hyphen is added when the word is made up of letters and there is room for it. Anything else,
+// such as a path or text in a script without spaces, is split without one.
+func breakWord(word string, width int) (size int, hyphen bool) {
+	letters := strings.IndexFunc(strings.TrimRightFunc(word, unicode.IsPunct), func(ch rune) bool {
+		return !isNarrowLetter(ch)
+	}) < 0
+	if letters && width >= 2 {
+		head, tail := splitAtWidth(word, width-1)
+		last, _ := utf8.DecodeLastRuneInString(head)
+		next, _ := utf8.DecodeRuneInString(tail)
+		if isNarrowLetter(last) && isNarrowLetter(next) {
+			return len(head), true
+		}
+	}
+	head, _ := splitAtWidth(word, width)
+	return len(head), false
+}
+
+// Letters of alphabetic scripts, where a hyphen is the usual way to split a word.
+func isNarrowLetter(ch rune) bool {
+	return unicode.IsLetter(ch) && runewidth.RuneWidth(ch) == 1
+}
@@ -0,0 +1,128 @@
+package termboxUI
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+func TestStringWidth(t *testing.T) {
+	tests := map[string]int{
+		"abc":  3,
+		"日本":   4,
+		"été": 3,
+		"👍🏽":   2,
+		"🇫🇷":   2,
+		"👩‍💻":  2,
+	}
+	for text, want := range tests {
+		if got := StringWidth(text); got != want {
+			t.Errorf("StringWidth(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestTruncateStringKeepsClustersWhole(t *testing.T) {
+	if got := TruncateString("日本語", 5); got != "日本" {
+		t.Errorf("TruncateString = %q, want %q", got, "日本")
+	}
+	if got := TruncateString("aéb", 2); got != "aé" {
+		t.Errorf("TruncateString = %q, want %q", got, "aé")
+	}
+}
+
+func TestDecomposedAccentsKeepTheirMark(t *testing.T) {
+	screen := NewSimulationScreen(10, 1)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	DrawText(0, 0, "cafe\u0301!", termbox.ColorDefault, termbox.ColorDefault)
+	if got := trimmedLine(screen, 0); got != "café!" {
+		t.Errorf("drew %q", got)
+	}
+}
+
+func TestLongClustersStayWhole(t *testing.T) {
+	// Far more combining marks than the part of the text that is segmented at first.
+	text := "a" + strings.Repeat("\u0300", 100) + "b"
+	if got := StringWidth(text); got != 2 {
+		t.Errorf("StringWidth = %d, want 2", got)
+	}
+	if got := TruncateString(text, 1); got != text[:len(text)-1] {
+		t.Errorf("TruncateString split the cluster after %d bytes", len(got))
+	}
+}
+
+func TestWrapString(t *testing.T) {
+	got := WrapString("  the quick brown fox", 11)
+	want := []string{"  the quick", "  brown fox"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapString = %q, want %q", got, want)
+	}
+
+	got = WrapString("abcdefghij", 5)
+	want = []string{"abcd-", "efgh-", "ij"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapString = %q, want %q", got, want)
+	}
+
+	got = WrapString("日本語日本語", 5)
+	want = []string{"日本", "語日", "本語"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WrapString = %q, want %q", got, want)
+	}
+}
+
+// The text of a row of the screen without the spaces around it.
+func trimmedLine(screen *SimulationScreen, y int) string {
+	return strings.TrimSpace(screen.Line(y))
+}
+
+func TestWideTextInTablesMenusAndPopups(t *testing.T) {
+	screen := NewSimulationScreen(30, 12)
+	SetScreen(screen)
+	defer SetScreen(nil)
+
+	table := CreateTable(10, 1, 1, 1, nil, nil, false, false, termbox.ColorDefault, termbox.ColorDefault)
+	table.SetCell(0, 0, "日本語テキスト")
+	table.Draw(0, 0)
+	// The cell is twelve columns wide, so six of the wide characters fit.
+	if got := trimmedLine(screen, 0); got != "日本語テキス" {
+		t.Errorf("table cell shows %q", got)
+	}
+
+	menu := CreateMenu(14, 1, "メニュー", MenuList, false, termbox.ColorDefault, termbox.ColorDefault)
+	menu.InsertMenuOption(MenuInsertLast, MenuOption{Title: "日本語"})
+	menu.Draw(0, 2)
+	if got := screen.Line(2); !strings.HasPrefix(got, "   メニュー") {
+		t.Errorf("menu header is not centered by width: %q", got)
+	}
+	if got := trimmedLine(screen, 5); got != "1. 日本語" {
+		t.Errorf("menu option shows %q", got)
+	}
+
+	popup := CreatePopup("題名", "", PopupBottom, 3, 10, termbox.ColorDefault, termbox.ColorDefault)
+	popup.Draw(0, 0)
+	if got := screen.Line(11); !strings.Contains(got, "│  題名   │") {
+		t.Errorf("popup title is not centered by width: %q", got)
+	}
+}
+
+func TestTextAreaDeletesWholeClusters(t *testing.T) {
+	area := CreateTextArea(20, 3, "aéb", false, 0, termbox.ColorDefault, termbox.ColorDefault)
+	ev := make(chan UIEvent, 1)
+
+	area.CursorIndex = 3
+	area.HandleKey(termbox.KeyBackspace2, 0, ev)
+	if string(area.Value) != "ab" || area.CursorIndex != 1 {
+		t.Errorf("after Backspace: %q with the cursor at %d", string(area.Value), area.CursorIndex)
+	}
+
+	area.Value, area.CursorIndex = []rune("aéb"), 1
+	area.HandleKey(termbox.KeyDelete, 0, ev)
+	if string(area.Value) != "ab" || area.CursorIndex != 1 {
+		t.Errorf("after Delete: %q with the cursor at %d", string(area.Value), area.CursorIndex)
+	}
+}